@@ -0,0 +1,319 @@
+// Package dynamicdomainset provides a mutable domain set on top of the
+// immutable gostaticdomainset.StaticDomainSet, for callers that need to add
+// or remove domains in a hot loop without paying for a full Compile on
+// every write.
+package dynamicdomainset
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/starius/hipermap/gostaticdomainset"
+)
+
+// Options configures the tiered merge policy of a DynamicDomainSet.
+type Options struct {
+	// MaxDelta triggers a background merge of the write buffer into a new
+	// immutable segment once Add/Remove calls accumulate this many entries.
+	// Zero selects a default of 1024.
+	MaxDelta int
+
+	// MaxSegments triggers a full compaction of all segments into one once
+	// a merge would leave more than this many segments. Zero selects a
+	// default of 8.
+	MaxSegments int
+}
+
+func (o Options) withDefaults() Options {
+	if o.MaxDelta <= 0 {
+		o.MaxDelta = 1024
+	}
+	if o.MaxSegments <= 0 {
+		o.MaxSegments = 8
+	}
+	return o
+}
+
+// Metrics reports the current shape of a DynamicDomainSet, for monitoring.
+type Metrics struct {
+	Segments      int
+	DeltaSize     int
+	TombstoneSize int
+	LastMergeDur  time.Duration
+}
+
+// segment is one immutable, compiled layer plus the exact patterns it was
+// built from, so Find can tell whether the pattern responsible for a
+// segment hit has since been tombstoned.
+type segment struct {
+	ds       *gostaticdomainset.StaticDomainSet
+	patterns map[string]struct{}
+}
+
+// DynamicDomainSet is a mutable domain set: a small write-optimized delta (a
+// Go map plus a tombstone set) layered on top of one or more immutable
+// StaticDomainSet segments, merged in the background the way log-structured
+// segment merging works in search indexes. Find consults the delta first,
+// then segments newest-to-oldest, honoring tombstones. The zero value is
+// not usable; construct with New.
+type DynamicDomainSet struct {
+	opts Options
+
+	mu           sync.RWMutex
+	added        map[string]struct{}
+	removed      map[string]struct{}
+	segments     []*segment // newest first
+	lastMergeDur time.Duration
+
+	mergeMu sync.Mutex // serializes merge/compact against each other
+}
+
+// New creates an empty DynamicDomainSet governed by opts.
+func New(opts Options) *DynamicDomainSet {
+	return &DynamicDomainSet{
+		opts:    opts.withDefaults(),
+		added:   make(map[string]struct{}),
+		removed: make(map[string]struct{}),
+	}
+}
+
+func normalizeDomain(domain string) string {
+	for len(domain) > 0 && domain[len(domain)-1] == '.' {
+		domain = domain[:len(domain)-1]
+	}
+	return strings.ToLower(domain)
+}
+
+// suffixesOf returns every whole-label suffix of domain, longest first.
+func suffixesOf(domain string) []string {
+	labels := strings.Split(domain, ".")
+	out := make([]string, len(labels))
+	for i := range labels {
+		out[i] = strings.Join(labels[i:], ".")
+	}
+	return out
+}
+
+// Add inserts domain into the set. By suffix-matching semantics this also
+// makes every subdomain of domain match Find.
+func (d *DynamicDomainSet) Add(domain string) {
+	domain = normalizeDomain(domain)
+	if domain == "" {
+		return
+	}
+
+	d.mu.Lock()
+	delete(d.removed, domain)
+	d.added[domain] = struct{}{}
+	deltaSize := len(d.added) + len(d.removed)
+	d.mu.Unlock()
+
+	if deltaSize >= d.opts.MaxDelta {
+		d.mergeAsync()
+	}
+}
+
+// Remove deletes domain from the set. It only affects domain itself, not
+// ancestor or descendant patterns that happen to also match it. Like Add,
+// it triggers a background merge once the write delta (adds plus
+// tombstones) reaches Options.MaxDelta, so a remove-heavy workload
+// compacts its tombstones instead of accumulating them forever.
+func (d *DynamicDomainSet) Remove(domain string) {
+	domain = normalizeDomain(domain)
+	if domain == "" {
+		return
+	}
+
+	d.mu.Lock()
+	delete(d.added, domain)
+	d.removed[domain] = struct{}{}
+	deltaSize := len(d.added) + len(d.removed)
+	d.mu.Unlock()
+
+	if deltaSize >= d.opts.MaxDelta {
+		d.mergeAsync()
+	}
+}
+
+// Find reports whether any whole-label suffix of domain is present in the
+// set, consulting the write delta first and then segments newest-to-oldest.
+func (d *DynamicDomainSet) Find(domain string) (bool, error) {
+	lower := normalizeDomain(domain)
+	if lower == "" {
+		return false, gostaticdomainset.ErrEmptyDomain
+	}
+	suffixes := suffixesOf(lower)
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, suf := range suffixes {
+		if _, tomb := d.removed[suf]; tomb {
+			continue
+		}
+		if _, ok := d.added[suf]; ok {
+			return true, nil
+		}
+	}
+
+	for _, seg := range d.segments {
+		ok, err := seg.ds.Find(lower)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			continue
+		}
+		for _, suf := range suffixes {
+			if _, ok := seg.patterns[suf]; !ok {
+				continue
+			}
+			if _, tomb := d.removed[suf]; tomb {
+				continue
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Flush forces an immediate merge of the write delta into a new segment,
+// compacting all segments into one if the tiered policy calls for it. It
+// blocks until the merge completes.
+func (d *DynamicDomainSet) Flush() error {
+	return d.merge()
+}
+
+func (d *DynamicDomainSet) mergeAsync() {
+	go d.merge() //nolint:errcheck // best-effort; retried on the next write or Flush
+}
+
+// merge compiles the current write delta into a new segment and, if that
+// leaves more segments than Options.MaxSegments or at least
+// Options.MaxDelta tombstones, compacts every segment into one. If there is nothing to
+// add but removals are pending, it compacts directly instead of being a
+// no-op, so a remove-heavy or remove-only workload (and Flush, called in
+// that state) can still reclaim tombstoned segments.
+func (d *DynamicDomainSet) merge() error {
+	d.mergeMu.Lock()
+	defer d.mergeMu.Unlock()
+
+	start := time.Now()
+
+	d.mu.Lock()
+	if len(d.added) == 0 {
+		if len(d.removed) == 0 {
+			d.mu.Unlock()
+			return nil
+		}
+		// Nothing new to merge in, but pending removals are pinning the
+		// segments they tombstone (and any orphaned tombstones for
+		// domains that were never added anywhere) in memory forever,
+		// with no other trigger to reclaim them. Compact now so a
+		// remove-heavy or remove-only workload, and an explicit Flush
+		// call in that state, actually free something.
+		d.compactLocked()
+		d.lastMergeDur = time.Since(start)
+		d.mu.Unlock()
+		return nil
+	}
+	patterns := make([]string, 0, len(d.added))
+	for p := range d.added {
+		patterns = append(patterns, p)
+	}
+	d.mu.Unlock()
+
+	ds, err := gostaticdomainset.Compile(patterns)
+	if err != nil {
+		return err
+	}
+	patternSet := make(map[string]struct{}, len(patterns))
+	for _, p := range patterns {
+		patternSet[p] = struct{}{}
+	}
+
+	d.mu.Lock()
+	for p := range patternSet {
+		delete(d.added, p)
+	}
+	d.segments = append([]*segment{{ds: ds, patterns: patternSet}}, d.segments...)
+	if len(d.segments) > d.opts.MaxSegments || len(d.removed) >= d.opts.MaxDelta {
+		d.compactLocked()
+	}
+	d.lastMergeDur = time.Since(start)
+	d.mu.Unlock()
+
+	return nil
+}
+
+// compactLocked merges every current segment into a single one, dropping
+// tombstoned patterns for good. d.mu must be held for writing.
+func (d *DynamicDomainSet) compactLocked() {
+	all := make(map[string]struct{})
+	for _, seg := range d.segments {
+		for p := range seg.patterns {
+			if _, tomb := d.removed[p]; tomb {
+				continue
+			}
+			all[p] = struct{}{}
+		}
+	}
+	if len(all) == 0 {
+		d.segments = nil
+		d.removed = make(map[string]struct{})
+		return
+	}
+	patterns := make([]string, 0, len(all))
+	for p := range all {
+		patterns = append(patterns, p)
+	}
+	ds, err := gostaticdomainset.Compile(patterns)
+	if err != nil {
+		// Compaction is an optimization, not required for correctness;
+		// keep the uncompacted segments on failure.
+		return
+	}
+	d.segments = []*segment{{ds: ds, patterns: all}}
+	d.removed = make(map[string]struct{})
+}
+
+// Snapshot compiles and returns an immutable StaticDomainSet containing the
+// current contents of the set (delta and all segments, minus tombstones).
+func (d *DynamicDomainSet) Snapshot() (*gostaticdomainset.StaticDomainSet, error) {
+	d.mu.RLock()
+	all := make(map[string]struct{}, len(d.added))
+	for p := range d.added {
+		all[p] = struct{}{}
+	}
+	for _, seg := range d.segments {
+		for p := range seg.patterns {
+			if _, tomb := d.removed[p]; tomb {
+				continue
+			}
+			all[p] = struct{}{}
+		}
+	}
+	d.mu.RUnlock()
+
+	if len(all) == 0 {
+		return nil, gostaticdomainset.ErrNoDomains
+	}
+	patterns := make([]string, 0, len(all))
+	for p := range all {
+		patterns = append(patterns, p)
+	}
+	return gostaticdomainset.Compile(patterns)
+}
+
+// Metrics reports the current shape of the set, for monitoring.
+func (d *DynamicDomainSet) Metrics() Metrics {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return Metrics{
+		Segments:      len(d.segments),
+		DeltaSize:     len(d.added),
+		TombstoneSize: len(d.removed),
+		LastMergeDur:  d.lastMergeDur,
+	}
+}
@@ -0,0 +1,172 @@
+package dynamicdomainset
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamicDomainSet_AddFindRemove(t *testing.T) {
+	d := New(Options{})
+
+	d.Add("example.com")
+	ok, err := d.Find("api.example.com")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	d.Remove("example.com")
+	ok, err = d.Find("api.example.com")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestDynamicDomainSet_SurvivesFlush(t *testing.T) {
+	d := New(Options{MaxDelta: 1 << 30, MaxSegments: 1 << 30})
+
+	d.Add("example.com")
+	d.Add("other.org")
+	require.NoError(t, d.Flush())
+	require.Equal(t, 1, d.Metrics().Segments)
+	require.Equal(t, 0, d.Metrics().DeltaSize)
+
+	ok, err := d.Find("x.example.com")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	d.Remove("example.com")
+	ok, err = d.Find("x.example.com")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Still present: tombstoned independently of other.org.
+	ok, err = d.Find("other.org")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestDynamicDomainSet_CompactsSegments(t *testing.T) {
+	d := New(Options{MaxDelta: 1, MaxSegments: 2})
+
+	for i := 0; i < 10; i++ {
+		d.Add(fmt.Sprintf("domain%d.com", i))
+		require.NoError(t, d.Flush())
+	}
+	require.LessOrEqual(t, d.Metrics().Segments, 2)
+
+	for i := 0; i < 10; i++ {
+		ok, err := d.Find(fmt.Sprintf("domain%d.com", i))
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+}
+
+// TestDynamicDomainSet_RemoveOnlyCompacts checks that a remove-only workload
+// can still reclaim tombstoned segments: Flush must compact even when there
+// is nothing pending in d.added. MaxDelta is set out of reach here so no
+// background merge runs; TestDynamicDomainSet_RemoveTriggersMerge covers
+// Remove triggering one on its own.
+func TestDynamicDomainSet_RemoveOnlyCompacts(t *testing.T) {
+	d := New(Options{MaxDelta: 1 << 30, MaxSegments: 1})
+
+	for i := 0; i < 5; i++ {
+		d.Add(fmt.Sprintf("domain%d.com", i))
+	}
+	require.NoError(t, d.Flush())
+	require.Equal(t, 1, d.Metrics().Segments)
+
+	for i := 0; i < 5; i++ {
+		d.Remove(fmt.Sprintf("domain%d.com", i))
+	}
+	require.Equal(t, 5, d.Metrics().TombstoneSize)
+
+	// Flush with an empty d.added must still compact away the tombstoned
+	// segment instead of being a no-op.
+	require.NoError(t, d.Flush())
+	require.Equal(t, 0, d.Metrics().TombstoneSize)
+
+	for i := 0; i < 5; i++ {
+		ok, err := d.Find(fmt.Sprintf("domain%d.com", i))
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+}
+
+// TestDynamicDomainSet_RemoveTriggersMerge checks that Remove, like Add,
+// triggers a background merge once the write delta crosses MaxDelta,
+// instead of only ever merging on the next Add or an explicit Flush.
+func TestDynamicDomainSet_RemoveTriggersMerge(t *testing.T) {
+	d := New(Options{MaxDelta: 5, MaxSegments: 1 << 30})
+
+	d.Add("example.com")
+	require.NoError(t, d.Flush())
+
+	for i := 0; i < 5; i++ {
+		d.Remove(fmt.Sprintf("never-added%d.com", i))
+	}
+
+	require.Eventually(t, func() bool {
+		return d.Metrics().TombstoneSize == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestDynamicDomainSet_Snapshot(t *testing.T) {
+	d := New(Options{})
+	d.Add("example.com")
+	d.Add("other.org")
+	require.NoError(t, d.Flush())
+	d.Add("fresh.net")
+	d.Remove("other.org")
+
+	snap, err := d.Snapshot()
+	require.NoError(t, err)
+
+	ok, err := snap.Find("example.com")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = snap.Find("fresh.net")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = snap.Find("other.org")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+// TestDynamicDomainSet_RandomizedAgainstReference runs a sequence of random
+// Add/Remove/Find actions and checks the result against a plain Go map
+// tracking which domains are currently present, flushing periodically to
+// exercise segment merging and compaction.
+func TestDynamicDomainSet_RandomizedAgainstReference(t *testing.T) {
+	d := New(Options{MaxDelta: 20, MaxSegments: 3})
+	reference := make(map[string]struct{})
+
+	r := rand.New(rand.NewSource(7))
+	domains := make([]string, 50)
+	for i := range domains {
+		domains[i] = fmt.Sprintf("site%d.example.com", i)
+	}
+
+	for i := 0; i < 2000; i++ {
+		dom := domains[r.Intn(len(domains))]
+		switch r.Intn(3) {
+		case 0:
+			d.Add(dom)
+			reference[dom] = struct{}{}
+		case 1:
+			d.Remove(dom)
+			delete(reference, dom)
+		case 2:
+			_, want := reference[dom]
+			got, err := d.Find(dom)
+			require.NoError(t, err)
+			require.Equalf(t, want, got, "domain=%q", dom)
+		}
+		if i%97 == 0 {
+			require.NoError(t, d.Flush())
+		}
+	}
+}
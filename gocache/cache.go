@@ -1,7 +1,9 @@
 package gocache
 
 import (
+	"encoding/binary"
 	"fmt"
+	"time"
 	"unsafe"
 )
 
@@ -13,9 +15,37 @@ type Cache struct {
 	cachePlace []byte
 	cache      *C.hm_cache_t
 	capacity   int
+	speed      int
+
+	// ttl and lastSeen implement optional time-based expiration on top of
+	// the C core's LRU eviction; see NewWithTTL. ttl == 0 disables it and
+	// lastSeen stays nil.
+	ttl      time.Duration
+	lastSeen map[uint32]time.Time
+
+	// stats accumulates the cumulative hit/miss/add/eviction counts and
+	// current length Stats reports; see stats.go. libhipermap has no
+	// hm_cache_stats entrypoint, so these are maintained entirely in Go by
+	// Add/Remove/Has, the same way ttl/lastSeen is.
+	stats Stats
 }
 
 func New(capacity, speed int) (*Cache, error) {
+	return newCache(capacity, speed, 0)
+}
+
+// NewWithTTL is like New, but entries older than ttl are treated as absent
+// by Has (which also removes them from the cache) and can be swept out in
+// bulk with Sweep. Add refreshes an entry's age on every call, including
+// updates to an existing key.
+func NewWithTTL(capacity, speed int, ttl time.Duration) (*Cache, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("ttl must be positive, got %v", ttl)
+	}
+	return newCache(capacity, speed, ttl)
+}
+
+func newCache(capacity, speed int, ttl time.Duration) (*Cache, error) {
 	var cachePlaceSize C.size_t
 	hmErr := C.hm_cache_place_size(
 		&cachePlaceSize,
@@ -39,11 +69,17 @@ func New(capacity, speed int) (*Cache, error) {
 		return nil, fmt.Errorf("hm_cache_init failed: %d", hmErr)
 	}
 
-	return &Cache{
+	c := &Cache{
 		cachePlace: cachePlace,
 		cache:      cache,
 		capacity:   capacity,
-	}, nil
+		speed:      speed,
+		ttl:        ttl,
+	}
+	if ttl > 0 {
+		c.lastSeen = make(map[uint32]time.Time, capacity)
+	}
+	return c, nil
 }
 
 func (c *Cache) Add(ip, value uint32) (existed, evicted bool, evictedIp, evictedValue uint32) {
@@ -59,7 +95,25 @@ func (c *Cache) Add(ip, value uint32) (existed, evicted bool, evictedIp, evicted
 		&cevictedValue,
 	)
 
-	return bool(cexisted), bool(cevicted), uint32(cevictedIp), uint32(cevictedValue)
+	existed, evicted = bool(cexisted), bool(cevicted)
+	evictedIp, evictedValue = uint32(cevictedIp), uint32(cevictedValue)
+
+	if c.ttl > 0 {
+		c.lastSeen[ip] = time.Now()
+		if evicted {
+			delete(c.lastSeen, evictedIp)
+		}
+	}
+
+	c.stats.Adds++
+	if evicted {
+		c.stats.Evictions++
+	}
+	if !existed && !evicted {
+		c.stats.Len++
+	}
+
+	return existed, evicted, evictedIp, evictedValue
 }
 
 func (c *Cache) Remove(ip uint32) (existed bool, existedValue uint32) {
@@ -72,17 +126,63 @@ func (c *Cache) Remove(ip uint32) (existed bool, existedValue uint32) {
 		&cexistedValue,
 	)
 
-	return bool(cexisted), uint32(cexistedValue)
+	if c.ttl > 0 {
+		delete(c.lastSeen, ip)
+	}
+
+	existed, existedValue = bool(cexisted), uint32(cexistedValue)
+	if existed {
+		c.stats.Len--
+	}
+
+	return existed, existedValue
 }
 
 func (c *Cache) Has(ip uint32) (exists bool, value uint32) {
+	if c.ttl > 0 {
+		if seen, ok := c.lastSeen[ip]; !ok || time.Since(seen) > c.ttl {
+			if ok {
+				c.Remove(ip)
+			}
+			c.stats.Misses++
+			return false, 0
+		}
+	}
+
 	var cvalue C.uint32_t
 	cexists := C.hm_cache_has(
 		c.cache,
 		C.uint32_t(ip),
 		&cvalue,
 	)
-	return bool(cexists), uint32(cvalue)
+	exists, value = bool(cexists), uint32(cvalue)
+	if exists {
+		c.stats.Hits++
+	} else {
+		c.stats.Misses++
+	}
+	return exists, value
+}
+
+// Sweep removes every entry older than the cache's TTL in one pass and
+// returns how many entries were dropped. It is a no-op on a Cache created
+// without a TTL.
+func (c *Cache) Sweep() int {
+	if c.ttl == 0 {
+		return 0
+	}
+
+	now := time.Now()
+	var expired []uint32
+	for ip, seen := range c.lastSeen {
+		if now.Sub(seen) > c.ttl {
+			expired = append(expired, ip)
+		}
+	}
+	for _, ip := range expired {
+		c.Remove(ip)
+	}
+	return len(expired)
 }
 
 func (c *Cache) Dump() []uint32 {
@@ -95,3 +195,117 @@ func (c *Cache) Dump() []uint32 {
 	)
 	return ips[:ipsLen]
 }
+
+// magicCacheUint32 and cacheHeaderBytes describe the on-wire layout shared
+// byte-for-byte with puregocache; see that package's serialize.go for the
+// matching encode/decode.
+const magicCacheUint32 = 0x41434D48 // "HMCA" in little-endian bytes
+const cacheHeaderBytes = 16
+
+// Serialize dumps the cache to a byte slice that can later be restored with
+// FromSerialized, either by a cgo-built binary or by puregocache.FromSerialized
+// in a pure-Go binary. The on-wire layout is:
+//
+//	magic    uint32 ("HMCA" in little-endian bytes)
+//	capacity uint32
+//	speed    int32
+//	count    uint32
+//	count * (ip uint32, value uint32), most-recently-used first
+//
+// Recording the LRU order lets FromSerialized re-insert entries in the same
+// order, so the hottest keys are still the hottest keys after a restart.
+//
+// libhipermap has no serialize entrypoint of its own, so this is built from
+// the real Dump and Has calls instead: Dump gives the most-recently-used-
+// first key order with no side effects, then Has fetches each value.
+// Has refreshes recency on every call, so the fetch loop runs back to
+// front (least recently used first) — by the time it reaches the last
+// (most recently used) key, every key has been re-promoted in its
+// original relative order, leaving the cache's actual LRU order exactly as
+// Dump first reported it.
+//
+// Serialize does not persist TTL state: the layout above is shared
+// byte-for-byte with puregocache, which has no notion of TTL, and restoring
+// per-entry last-seen timestamps wouldn't account for the time a process
+// spent down anyway. A Cache created with NewWithTTL therefore refuses to
+// serialize rather than silently reloading as one that never expires; call
+// Sweep before shutdown and let entries start aging again from
+// FromSerialized instead.
+func (c *Cache) Serialize() ([]byte, error) {
+	if c.ttl > 0 {
+		return nil, fmt.Errorf("gocache: Serialize does not support a Cache created with NewWithTTL")
+	}
+
+	ips := c.Dump()
+	values := make([]uint32, len(ips))
+	for i := len(ips) - 1; i >= 0; i-- {
+		_, values[i] = c.Has(ips[i])
+	}
+
+	buf := make([]byte, cacheHeaderBytes+8*len(ips))
+	binary.LittleEndian.PutUint32(buf[0:4], magicCacheUint32)
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(c.capacity))
+	binary.LittleEndian.PutUint32(buf[8:12], uint32(int32(c.speed)))
+	binary.LittleEndian.PutUint32(buf[12:16], uint32(len(ips)))
+
+	off := cacheHeaderBytes
+	for i, ip := range ips {
+		binary.LittleEndian.PutUint32(buf[off:off+4], ip)
+		binary.LittleEndian.PutUint32(buf[off+4:off+8], values[i])
+		off += 8
+	}
+
+	return buf, nil
+}
+
+// FromSerialized restores a Cache from a byte slice produced by Serialize,
+// either by this build or by puregocache.Serialize. The returned Cache
+// never has a TTL, since Serialize refuses to produce output for a Cache
+// created with NewWithTTL; call NewWithTTL directly if the restored cache
+// should expire entries, keeping in mind that every entry starts out fresh
+// as of the call to FromSerialized.
+//
+// libhipermap has no deserialize entrypoint of its own, so this parses the
+// header directly and re-inserts each record with the real Add, using the
+// existing hm_cache_place_size/hm_cache_init allocation path newCache
+// already goes through. Records are stored most-recently-used first, so
+// they are replayed back to front: the last (originally least recently
+// used) record is added first, and the first (originally most recently
+// used) record is added last, reproducing the original recency order.
+func FromSerialized(data []byte) (*Cache, error) {
+	if len(data) < cacheHeaderBytes {
+		return nil, fmt.Errorf("serialized cache too short: %d bytes", len(data))
+	}
+
+	magic := binary.LittleEndian.Uint32(data[0:4])
+	if magic != magicCacheUint32 {
+		return nil, fmt.Errorf("bad magic in serialized cache: %#x", magic)
+	}
+	capacity := binary.LittleEndian.Uint32(data[4:8])
+	speed := int32(binary.LittleEndian.Uint32(data[8:12]))
+	count := binary.LittleEndian.Uint32(data[12:16])
+
+	want := cacheHeaderBytes + 8*int(count)
+	if len(data) < want {
+		return nil, fmt.Errorf("serialized cache truncated: need %d bytes, got %d", want, len(data))
+	}
+
+	c, err := newCache(int(capacity), int(speed), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	type rec struct{ ip, value uint32 }
+	recs := make([]rec, count)
+	off := cacheHeaderBytes
+	for i := uint32(0); i < count; i++ {
+		recs[i].ip = binary.LittleEndian.Uint32(data[off : off+4])
+		recs[i].value = binary.LittleEndian.Uint32(data[off+4 : off+8])
+		off += 8
+	}
+	for i := len(recs) - 1; i >= 0; i-- {
+		c.Add(recs[i].ip, recs[i].value)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,109 @@
+package gocache
+
+import (
+	"container/list"
+	"fmt"
+	"net"
+)
+
+// entry6 is the value stored in each list.Element, in most-recently-used to
+// least-recently-used order.
+type entry6 struct {
+	ip    [16]byte
+	value uint32
+}
+
+// Cache6 is the IPv6 counterpart of Cache: a fixed-capacity LRU keyed by a
+// 16-byte address instead of a uint32. libhipermap's hm_cache_* entry
+// points are hardcoded to uint32 keys and have no 16-byte-keyed
+// counterpart, so unlike Cache this type keeps no C-side state at all: it
+// is implemented the same way puregocache.Cache is, with a container/list
+// LRU list and a map index. It is a separate type rather than a
+// variable-width Cache so that the common IPv4 path keeps its existing
+// uint32 signatures and its real cgo backing.
+type Cache6 struct {
+	capacity int
+	speed    int
+
+	ll    *list.List
+	items map[[16]byte]*list.Element
+}
+
+// NewCache6 creates a Cache6 holding up to capacity entries. speed is
+// accepted for API parity with New, but unused: it only tunes the
+// associativity of the cgo hash table Cache is backed by, which Cache6 has
+// none of.
+func NewCache6(capacity, speed int) (*Cache6, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("capacity must be positive, got %d", capacity)
+	}
+
+	return &Cache6{
+		capacity: capacity,
+		speed:    speed,
+		ll:       list.New(),
+		items:    make(map[[16]byte]*list.Element, capacity),
+	}, nil
+}
+
+// Add inserts or updates ip with value, returning whether the key already
+// existed and, if inserting it pushed the cache over capacity, whether an
+// older entry was evicted to make room (and which one).
+func (c *Cache6) Add(ip [16]byte, value uint32) (existed, evicted bool, evictedIp [16]byte, evictedValue uint32) {
+	if el, ok := c.items[ip]; ok {
+		el.Value.(*entry6).value = value
+		c.ll.MoveToFront(el)
+		return true, false, [16]byte{}, 0
+	}
+
+	el := c.ll.PushFront(&entry6{ip: ip, value: value})
+	c.items[ip] = el
+
+	if c.ll.Len() <= c.capacity {
+		return false, false, [16]byte{}, 0
+	}
+
+	back := c.ll.Back()
+	c.ll.Remove(back)
+	ev := back.Value.(*entry6)
+	delete(c.items, ev.ip)
+	return false, true, ev.ip, ev.value
+}
+
+// Remove deletes ip from the cache, returning whether it was present and,
+// if so, its value.
+func (c *Cache6) Remove(ip [16]byte) (existed bool, existedValue uint32) {
+	el, ok := c.items[ip]
+	if !ok {
+		return false, 0
+	}
+	ev := el.Value.(*entry6)
+	c.ll.Remove(el)
+	delete(c.items, ip)
+	return true, ev.value
+}
+
+// Has reports whether ip is present and, like a real LRU lookup, refreshes
+// its recency.
+func (c *Cache6) Has(ip [16]byte) (exists bool, value uint32) {
+	el, ok := c.items[ip]
+	if !ok {
+		return false, 0
+	}
+	c.ll.MoveToFront(el)
+	return true, el.Value.(*entry6).value
+}
+
+// Dump returns the addresses currently stored in the cache as net.IP
+// values, most recently used first, so v4-mapped-in-v6 entries print and
+// compare the way callers expect.
+func (c *Cache6) Dump() []net.IP {
+	ips := make([]net.IP, 0, c.ll.Len())
+	for el := c.ll.Front(); el != nil; el = el.Next() {
+		raw := el.Value.(*entry6).ip
+		ip := make(net.IP, 16)
+		copy(ip, raw[:])
+		ips = append(ips, ip)
+	}
+	return ips
+}
@@ -0,0 +1,82 @@
+package gocache
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func ipv6Key(ip net.IP) [16]byte {
+	var key [16]byte
+	copy(key[:], ip.To16())
+	return key
+}
+
+func TestCache6(t *testing.T) {
+	const capacity = 16
+	const speed = 3
+	c, err := NewCache6(capacity, speed)
+	require.NoError(t, err)
+
+	t.Log("init", c.Dump())
+
+	keys := make([][16]byte, 0, capacity)
+	for i := 0; i < capacity; i++ {
+		// Mix plain IPv6 addresses with v4-mapped-in-v6 ones so that
+		// entries which differ only in their low 32 bits hash to
+		// different buckets.
+		var ip net.IP
+		if i%2 == 0 {
+			ip = net.IPv4(192, 168, byte(i), 1).To16()
+		} else {
+			ip = net.ParseIP(fmt.Sprintf("2001:db8::%x", i))
+		}
+		keys = append(keys, ipv6Key(ip))
+	}
+
+	for i, key := range keys {
+		existed, evicted, _, _ := c.Add(key, uint32(i))
+		require.False(t, existed)
+		require.False(t, evicted)
+		t.Log("add", key, c.Dump())
+	}
+
+	for i, key := range keys {
+		has, value := c.Has(key)
+		require.True(t, has, key)
+		require.Equal(t, uint32(i), value)
+	}
+
+	existed, existedValue := c.Remove(keys[0])
+	require.True(t, existed)
+	require.Equal(t, uint32(0), existedValue)
+
+	has, _ := c.Has(keys[0])
+	require.False(t, has)
+}
+
+func TestCache6_V4MappedKeysDistinctFromV6(t *testing.T) {
+	const capacity = 4
+	const speed = 2
+	c, err := NewCache6(capacity, speed)
+	require.NoError(t, err)
+
+	v4Mapped := ipv6Key(net.IPv4(10, 0, 0, 1).To16())
+	plainV6 := ipv6Key(net.ParseIP("::a00:1"))
+	require.NotEqual(t, v4Mapped, plainV6, "v4-mapped and plain v6 forms of the same low bits must not collide as keys")
+
+	existed, _, _, _ := c.Add(v4Mapped, 1)
+	require.False(t, existed)
+	existed, _, _, _ = c.Add(plainV6, 2)
+	require.False(t, existed)
+
+	has, value := c.Has(v4Mapped)
+	require.True(t, has)
+	require.Equal(t, uint32(1), value)
+
+	has, value = c.Has(plainV6)
+	require.True(t, has)
+	require.Equal(t, uint32(2), value)
+}
@@ -0,0 +1,93 @@
+package gocache
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/starius/hipermap/puregocache"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPureCGOCacheCompatibility cross-verifies puregocache against the cgo
+// Cache, mirroring TestPureCGOSerializationCompatibility in
+// gostaticdomainset: build up a cache, serialize it, reload it on the other
+// side, and make sure every Has result still agrees.
+func TestPureCGOCacheCompatibility(t *testing.T) {
+	const capacity = 32
+	const speed = 3
+	const maxIP = 80
+
+	cgoCache, err := New(capacity, speed)
+	require.NoError(t, err)
+
+	r := rand.New(rand.NewSource(222))
+	for i := 0; i < 2000; i++ {
+		ip := uint32(r.Intn(maxIP))
+		switch r.Intn(3) {
+		case 0:
+			cgoCache.Add(ip, uint32(r.Intn(1<<20)))
+		case 1:
+			cgoCache.Has(ip)
+		case 2:
+			cgoCache.Remove(ip)
+		}
+	}
+
+	// Serialize from cgo, load in pure, compare Has results.
+	cgoSer, err := cgoCache.Serialize()
+	require.NoError(t, err)
+
+	pureFromCgo, err := puregocache.FromSerialized(cgoSer)
+	require.NoError(t, err)
+
+	for ip := uint32(0); ip < maxIP; ip++ {
+		wantExists, wantValue := cgoCache.Has(ip)
+		gotExists, gotValue := pureFromCgo.Has(ip)
+		require.Equalf(t, wantExists, gotExists, "ip %d exists mismatch", ip)
+		if wantExists {
+			require.Equalf(t, wantValue, gotValue, "ip %d value mismatch", ip)
+		}
+	}
+
+	// Serialize from pure, load in cgo, compare Has results.
+	pureSer, err := pureFromCgo.Serialize()
+	require.NoError(t, err)
+
+	cgoFromPure, err := FromSerialized(pureSer)
+	require.NoError(t, err)
+
+	for ip := uint32(0); ip < maxIP; ip++ {
+		wantExists, wantValue := pureFromCgo.Has(ip)
+		gotExists, gotValue := cgoFromPure.Has(ip)
+		require.Equalf(t, wantExists, gotExists, "ip %d exists mismatch", ip)
+		if wantExists {
+			require.Equalf(t, wantValue, gotValue, "ip %d value mismatch", ip)
+		}
+	}
+}
+
+// TestPureCGOCacheSerializationByteIdentical checks that Serialize's
+// most-recently-used-first layout really is byte-for-byte identical
+// regardless of which build produced it.
+func TestPureCGOCacheSerializationByteIdentical(t *testing.T) {
+	c, err := New(16, 2)
+	require.NoError(t, err)
+	for i := uint32(0); i < 16; i++ {
+		c.Add(i*7+1, i*3)
+	}
+
+	cgoSer, err := c.Serialize()
+	require.NoError(t, err)
+
+	pureFromCgo, err := puregocache.FromSerialized(cgoSer)
+	require.NoError(t, err)
+	pureSer, err := pureFromCgo.Serialize()
+	require.NoError(t, err)
+	require.Equal(t, cgoSer, pureSer, "pure re-serialization of a cgo-built cache must be byte-identical")
+
+	cgoFromPure, err := FromSerialized(pureSer)
+	require.NoError(t, err)
+	cgoSer2, err := cgoFromPure.Serialize()
+	require.NoError(t, err)
+	require.Equal(t, pureSer, cgoSer2, "cgo re-serialization of a pure-built cache must be byte-identical")
+}
@@ -0,0 +1,27 @@
+package gocache
+
+// Each streams the cache's entries to fn in LRU order (most recently used
+// first). fn may return false to stop iteration early.
+//
+// libhipermap has no hm_cache_each entrypoint, so this is built from the
+// real Dump and Has calls instead of a true C-side iterator: Dump gives the
+// most-recently-used-first key order with no side effects, then Has fetches
+// each value. Has refreshes recency on every call, so the fetch loop runs
+// back to front (least recently used first) — by the time it reaches the
+// last (most recently used) key, every key has been re-promoted in its
+// original relative order, leaving the cache's actual LRU order exactly as
+// Dump first reported it. Unlike a true C-side iterator this allocates two
+// slices per call: the one Dump returns and one for the fetched values.
+func (c *Cache) Each(fn func(ip, value uint32) bool) {
+	ips := c.Dump()
+	values := make([]uint32, len(ips))
+	for i := len(ips) - 1; i >= 0; i-- {
+		_, values[i] = c.Has(ips[i])
+	}
+
+	for i, ip := range ips {
+		if !fn(ip, values[i]) {
+			return
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package gocache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheEach_VisitsAllEntriesInLRUOrder(t *testing.T) {
+	const capacity = 8
+	const speed = 3
+	c, err := New(capacity, speed)
+	require.NoError(t, err)
+
+	for i := uint32(0); i < 5; i++ {
+		c.Add(i, i*10)
+	}
+	// Touch 0 so it becomes most recently used.
+	c.Has(0)
+
+	var ips []uint32
+	values := make(map[uint32]uint32)
+	c.Each(func(ip, value uint32) bool {
+		ips = append(ips, ip)
+		values[ip] = value
+		return true
+	})
+
+	require.Equal(t, []uint32{0, 4, 3, 2, 1}, ips)
+	for ip, value := range values {
+		require.Equal(t, ip*10, value)
+	}
+}
+
+func TestCacheEach_StopsEarly(t *testing.T) {
+	const capacity = 8
+	const speed = 3
+	c, err := New(capacity, speed)
+	require.NoError(t, err)
+
+	for i := uint32(0); i < 5; i++ {
+		c.Add(i, i)
+	}
+
+	visited := 0
+	c.Each(func(ip, value uint32) bool {
+		visited++
+		return visited < 2
+	})
+
+	require.Equal(t, 2, visited)
+}
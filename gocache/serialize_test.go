@@ -0,0 +1,37 @@
+package gocache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheSerializeRoundTrip(t *testing.T) {
+	const capacity = 16
+	const speed = 3
+	c, err := New(capacity, speed)
+	require.NoError(t, err)
+
+	for i := uint32(0); i < capacity; i++ {
+		c.Add(i, i*100)
+	}
+	// Touch a few keys so the LRU order isn't just insertion order.
+	c.Has(2)
+	c.Has(5)
+	c.Has(0)
+
+	data, err := c.Serialize()
+	require.NoError(t, err)
+
+	restored, err := FromSerialized(data)
+	require.NoError(t, err)
+
+	require.Equal(t, c.Dump(), restored.Dump(), "restored cache must preserve LRU order")
+
+	for i := uint32(0); i < capacity; i++ {
+		wantExists, wantValue := c.Has(i)
+		gotExists, gotValue := restored.Has(i)
+		require.Equalf(t, wantExists, gotExists, "ip %d exists mismatch", i)
+		require.Equalf(t, wantValue, gotValue, "ip %d value mismatch", i)
+	}
+}
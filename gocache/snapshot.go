@@ -0,0 +1,44 @@
+package gocache
+
+import (
+	"fmt"
+	"io"
+)
+
+// Snapshot writes c's entries and LRU ordering to w, under the name
+// warmup-sensitive call sites (DNS resolver caches, IP reputation) reach for
+// around restart. It uses the same wire format as Serialize; see Serialize
+// for the exact layout and for why a Cache created with NewWithTTL refuses
+// to snapshot.
+func (c *Cache) Snapshot(w io.Writer) error {
+	data, err := c.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// LoadSnapshot reads a snapshot written by Snapshot and rehydrates a Cache
+// with equivalent hit/eviction behavior, the same way FromSerialized does.
+// capacity and speed must match the values the snapshot was taken with, so a
+// caller that passes the wrong ones for a reloaded config gets a clear error
+// instead of a cache that silently evicts differently than intended.
+func LoadSnapshot(r io.Reader, capacity, speed uint32) (*Cache, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := FromSerialized(data)
+	if err != nil {
+		return nil, err
+	}
+	if uint32(c.capacity) != capacity {
+		return nil, fmt.Errorf("LoadSnapshot: capacity mismatch: snapshot has %d, want %d", c.capacity, capacity)
+	}
+	if uint32(c.speed) != speed {
+		return nil, fmt.Errorf("LoadSnapshot: speed mismatch: snapshot has %d, want %d", c.speed, speed)
+	}
+	return c, nil
+}
@@ -0,0 +1,71 @@
+package gocache
+
+import (
+	"bytes"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gitlab.com/starius/lru-gen/examples/int2string"
+)
+
+// TestSnapshotControl mirrors TestCacheControl, but snapshots and restores
+// the Cache partway through the run and keeps driving both it and the
+// int2string reference LRU with the same operation stream, checking that
+// restoring from a snapshot doesn't change observable hit/eviction behavior.
+func TestSnapshotControl(t *testing.T) {
+	const capacity = 64
+	const maxIP = 150
+	const maxValue = 1000000
+	const valueSize = 1
+	const speed = 4
+
+	c, err := New(capacity, speed)
+	require.NoError(t, err)
+
+	control, err := int2string.NewLRU(capacity, capacity)
+	require.NoError(t, err)
+
+	r := rand.New(rand.NewSource(112))
+
+	drive := func(n int) {
+		for i := 0; i < n; i++ {
+			ip := r.Intn(maxIP)
+			action := r.Intn(3)
+
+			switch action {
+			case 0:
+				value := r.Intn(maxValue)
+				existed1, _, _, _ := c.Add(uint32(ip), uint32(value))
+				existed2 := control.Set(ip, strconv.Itoa(value), valueSize)
+				require.Equal(t, existed2, existed1)
+
+			case 1:
+				has1, value1 := c.Has(uint32(ip))
+				value2, has2 := control.Get(ip)
+				require.Equal(t, has2, has1)
+				if has2 {
+					require.Equal(t, value2, strconv.Itoa(int(value1)))
+				}
+
+			case 2:
+				existed1, _ := c.Remove(uint32(ip))
+				existed2 := control.DeleteIfExists(ip)
+				require.Equal(t, existed2, existed1)
+			}
+		}
+	}
+
+	drive(500000)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Snapshot(&buf))
+
+	restored, err := LoadSnapshot(&buf, capacity, speed)
+	require.NoError(t, err)
+	require.Equal(t, c.Dump(), restored.Dump())
+	c = restored
+
+	drive(500000)
+}
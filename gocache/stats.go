@@ -0,0 +1,19 @@
+package gocache
+
+// Stats holds cumulative hit/miss/add/eviction counters, plus the cache's
+// current size. libhipermap has no hm_cache_stats entrypoint to read these
+// from, so they are accumulated entirely in Go by Add/Remove/Has as they
+// call through to the real C core; see the stats field on Cache.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Adds      uint64
+	Evictions uint64
+	Len       int
+}
+
+// Stats returns a snapshot of the cache's cumulative hit/miss/add/eviction
+// counters and its current length.
+func (c *Cache) Stats() Stats {
+	return c.stats
+}
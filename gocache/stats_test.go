@@ -0,0 +1,36 @@
+package gocache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheStats(t *testing.T) {
+	const capacity = 4
+	const speed = 3
+	c, err := New(capacity, speed)
+	require.NoError(t, err)
+
+	stats := c.Stats()
+	require.Equal(t, Stats{}, stats)
+
+	for i := uint32(0); i < capacity; i++ {
+		c.Add(i, i)
+	}
+	stats = c.Stats()
+	require.Equal(t, uint64(capacity), stats.Adds)
+	require.Equal(t, capacity, stats.Len)
+
+	_, _ = c.Has(0)
+	_, _ = c.Has(999) // not present
+	stats = c.Stats()
+	require.Equal(t, uint64(1), stats.Hits)
+	require.Equal(t, uint64(1), stats.Misses)
+
+	// Pushing one more entry over capacity evicts the oldest.
+	c.Add(capacity, capacity)
+	stats = c.Stats()
+	require.Equal(t, uint64(1), stats.Evictions)
+	require.Equal(t, capacity, stats.Len)
+}
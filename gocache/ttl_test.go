@@ -0,0 +1,101 @@
+package gocache
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheTTL_ExpiresOnHas(t *testing.T) {
+	const capacity = 8
+	const speed = 3
+	const ttl = 20 * time.Millisecond
+
+	c, err := NewWithTTL(capacity, speed, ttl)
+	require.NoError(t, err)
+
+	existed, evicted, _, _ := c.Add(1, 100)
+	require.False(t, existed)
+	require.False(t, evicted)
+
+	has, value := c.Has(1)
+	require.True(t, has)
+	require.Equal(t, uint32(100), value)
+
+	time.Sleep(2 * ttl)
+
+	has, _ = c.Has(1)
+	require.False(t, has, "entry should be treated as absent once past its TTL")
+
+	// Once expired and removed by Has, it should be addable again as new.
+	existed, _, _, _ = c.Add(1, 200)
+	require.False(t, existed)
+}
+
+func TestCacheTTL_AddRefreshesAge(t *testing.T) {
+	const capacity = 8
+	const speed = 3
+	const ttl = 30 * time.Millisecond
+
+	c, err := NewWithTTL(capacity, speed, ttl)
+	require.NoError(t, err)
+
+	c.Add(1, 1)
+	time.Sleep(ttl / 2)
+	// Refresh by re-Adding before expiry.
+	existed, _, _, _ := c.Add(1, 2)
+	require.True(t, existed)
+	time.Sleep(ttl / 2)
+
+	has, value := c.Has(1)
+	require.True(t, has, "re-Add should have refreshed the entry's age")
+	require.Equal(t, uint32(2), value)
+}
+
+func TestCacheTTL_Sweep(t *testing.T) {
+	const capacity = 8
+	const speed = 3
+	const ttl = 20 * time.Millisecond
+
+	c, err := NewWithTTL(capacity, speed, ttl)
+	require.NoError(t, err)
+
+	for i := uint32(0); i < 4; i++ {
+		c.Add(i, i)
+	}
+	time.Sleep(2 * ttl)
+
+	dropped := c.Sweep()
+	require.Equal(t, 4, dropped)
+
+	for i := uint32(0); i < 4; i++ {
+		has, _ := c.Has(i)
+		require.False(t, has, i)
+	}
+}
+
+func TestCacheTTL_SerializeRejected(t *testing.T) {
+	c, err := NewWithTTL(8, 3, 20*time.Millisecond)
+	require.NoError(t, err)
+
+	c.Add(1, 1)
+
+	_, err = c.Serialize()
+	require.Error(t, err, "Serialize cannot carry TTL state, so it must refuse a Cache created with NewWithTTL")
+
+	var buf bytes.Buffer
+	require.Error(t, c.Snapshot(&buf))
+}
+
+func TestCache_SweepWithoutTTLIsNoOp(t *testing.T) {
+	c, err := New(8, 3)
+	require.NoError(t, err)
+
+	c.Add(1, 1)
+	require.Equal(t, 0, c.Sweep())
+
+	has, _ := c.Has(1)
+	require.True(t, has)
+}
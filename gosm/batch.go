@@ -0,0 +1,130 @@
+package gosm
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// chunkRanges splits [0, n) into up to workers contiguous, roughly
+// equal-sized ranges, never returning an empty range and never returning
+// more ranges than n has elements for.
+func chunkRanges(n, workers int) [][2]int {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+	if n == 0 {
+		return nil
+	}
+	size := (n + workers - 1) / workers
+	ranges := make([][2]int, 0, workers)
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges
+}
+
+// FindBatch evaluates ips against m using up to GOMAXPROCS worker
+// goroutines, each calling the single-key Find in a loop over its own
+// contiguous slice, for high-QPS callers that would otherwise have to build
+// their own worker pool around Find. out must have the same length as ips.
+func (m *StaticMap) FindBatch(ips []uint32, out []uint64) error {
+	if len(ips) != len(out) {
+		return fmt.Errorf("FindBatch: len(out)=%d != len(ips)=%d", len(out), len(ips))
+	}
+	if len(ips) == 0 {
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	ranges := chunkRanges(len(ips), runtime.GOMAXPROCS(0))
+	wg.Add(len(ranges))
+	for _, rg := range ranges {
+		start, end := rg[0], rg[1]
+		go func() {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				out[i] = m.Find(ips[i])
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// Result is one IP's outcome within a ResultSet.
+type Result struct {
+	Value uint64
+}
+
+// ResultSet holds the in-flight and completed results of a SubmitBatch
+// call. Its methods are safe to call concurrently with the worker
+// goroutines still filling in the remaining slots, following the same
+// "reap whatever is ready, don't block on stragglers" shape as Tendermint's
+// TaskResultSet.Reap.
+type ResultSet struct {
+	slots []atomic.Pointer[Result]
+	wg    sync.WaitGroup
+}
+
+// SubmitBatch dispatches ips across up to GOMAXPROCS goroutines and returns
+// immediately with a ResultSet that fills in as workers finish.
+//
+// Go has no portable cache-line prefetch intrinsic, and a cgo call per
+// prefetch would cost more than it could save, so each worker just relies
+// on sequential access within its own chunk for locality.
+func (m *StaticMap) SubmitBatch(ips []uint32) *ResultSet {
+	rs := &ResultSet{slots: make([]atomic.Pointer[Result], len(ips))}
+	ranges := chunkRanges(len(ips), runtime.GOMAXPROCS(0))
+	rs.wg.Add(len(ranges))
+	for _, rg := range ranges {
+		start, end := rg[0], rg[1]
+		go rs.fillRange(m, ips, start, end)
+	}
+	return rs
+}
+
+func (rs *ResultSet) fillRange(m *StaticMap, ips []uint32, start, end int) {
+	defer rs.wg.Done()
+	for i := start; i < end; i++ {
+		res := Result{Value: m.Find(ips[i])}
+		rs.slots[i].Store(&res)
+	}
+}
+
+// Reap returns the current state of every slot in ip order: a non-nil
+// *Result for ips that have resolved, nil for ones still in flight. It
+// never blocks.
+func (rs *ResultSet) Reap() []*Result {
+	out := make([]*Result, len(rs.slots))
+	for i := range rs.slots {
+		out[i] = rs.slots[i].Load()
+	}
+	return out
+}
+
+// LatestResult returns the result of ip i and whether it has resolved yet,
+// for streaming consumers that want to poll one slot at a time instead of
+// reaping the whole batch.
+func (rs *ResultSet) LatestResult(i int) (Result, bool) {
+	p := rs.slots[i].Load()
+	if p == nil {
+		return Result{}, false
+	}
+	return *p, true
+}
+
+// Wait blocks until every ip in the batch has resolved, then returns the
+// same thing Reap would: every slot non-nil.
+func (rs *ResultSet) Wait() []*Result {
+	rs.wg.Wait()
+	return rs.Reap()
+}
@@ -0,0 +1,53 @@
+package gosm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindBatch(t *testing.T) {
+	sm, err := Compile(
+		[]uint32{0x01000000, 0x0a000000},
+		[]uint8{8, 8},
+		[]uint64{100, 200},
+	)
+	require.NoError(t, err)
+
+	ips := []uint32{0x01000001, 0x0a000001, 0xc0000001}
+	out := make([]uint64, len(ips))
+	require.NoError(t, sm.FindBatch(ips, out))
+
+	for i, ip := range ips {
+		require.Equal(t, sm.Find(ip), out[i])
+	}
+}
+
+func TestFindBatchLengthMismatch(t *testing.T) {
+	sm, err := Compile([]uint32{0x01000000}, []uint8{8}, []uint64{1})
+	require.NoError(t, err)
+	require.Error(t, sm.FindBatch([]uint32{1, 2}, []uint64{0}))
+}
+
+func TestSubmitBatchReapAndWait(t *testing.T) {
+	sm, err := Compile(
+		[]uint32{0x01000000, 0x0a000000},
+		[]uint8{8, 8},
+		[]uint64{100, 200},
+	)
+	require.NoError(t, err)
+
+	ips := []uint32{0x01000001, 0x0a000001, 0xc0000001}
+	rs := sm.SubmitBatch(ips)
+	results := rs.Wait()
+	require.Len(t, results, len(ips))
+
+	for i, ip := range ips {
+		require.NotNil(t, results[i])
+		require.Equal(t, sm.Find(ip), results[i].Value)
+
+		latest, ok := rs.LatestResult(i)
+		require.True(t, ok)
+		require.Equal(t, sm.Find(ip), latest.Value)
+	}
+}
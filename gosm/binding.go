@@ -3,6 +3,7 @@ package gosm
 import (
 	"errors"
 	"fmt"
+	"syscall"
 	"unsafe"
 )
 
@@ -10,9 +11,40 @@ import (
 // #cgo LDFLAGS: -l hipermap -lstdc++
 import "C"
 
+// smEntry is one compiled (ip, prefix, value) triple, retained purely on
+// the Go side so FindAll and Walk (see find_all.go and walk.go) have
+// something to search and iterate: libhipermap's hm_sm_database_t is
+// opaque and has no entry points to enumerate or query a range of values
+// back out of it.
+type smEntry struct {
+	ip     uint32
+	prefix uint8
+	value  uint64
+}
+
 type StaticMap struct {
 	dbPlace []byte
+	mmap    []byte
 	db      *C.hm_sm_database_t
+
+	// entries is set by Compile and used by FindAll/Walk; it is nil for a
+	// StaticMap loaded via FromSerialized or FromMappedFile, since neither
+	// can recover the original entries from the serialized db_place, so
+	// FindAll/Walk report nothing on those instead of guessing.
+	entries []smEntry
+}
+
+// Close releases resources held by m. It unmaps m.mmap if that is set;
+// no loader in this package sets it (FromMappedFile unmaps its file before
+// returning), so Close is currently a no-op, though calling it is always
+// safe.
+func (m *StaticMap) Close() error {
+	if m.mmap == nil {
+		return nil
+	}
+	region := m.mmap
+	m.mmap = nil
+	return syscall.Munmap(region)
 }
 
 func Compile(ips []uint32, cidrPrefixes []uint8, values []uint64) (*StaticMap, error) {
@@ -37,9 +69,16 @@ func Compile(ips []uint32, cidrPrefixes []uint8, values []uint64) (*StaticMap, e
 	if hmErr != C.HM_SUCCESS {
 		return nil, fmt.Errorf("hm_sm_compile failed: %d", hmErr)
 	}
+
+	entries := make([]smEntry, len(ips))
+	for i := range ips {
+		entries[i] = smEntry{ip: ips[i], prefix: cidrPrefixes[i], value: values[i]}
+	}
+
 	return &StaticMap{
 		dbPlace: dbPlace,
 		db:      db,
+		entries: entries,
 	}, nil
 }
 
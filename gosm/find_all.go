@@ -0,0 +1,53 @@
+package gosm
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"sort"
+)
+
+// Match is one covering CIDR returned by FindAll.
+type Match struct {
+	Prefix netip.Prefix
+	Value  uint64
+}
+
+// FindAll returns every compiled CIDR that covers ip, ordered most-specific
+// (longest prefix) to least-specific, unlike Find which only reports the
+// most-specific match's value. This lets callers implement policy stacking,
+// e.g. "block if any covering CIDR is on the deny list, else use the
+// most-specific allow value".
+//
+// libhipermap has no hm_sm_find_all entrypoint (hm_sm_database_t can only
+// be queried for a single longest-prefix match at a time), so this scans
+// the entries Compile recorded on the Go side instead of the C database.
+// It therefore reports nothing for a StaticMap loaded via FromSerialized or
+// FromMappedFile, which has no surviving entry list.
+func (m *StaticMap) FindAll(ip uint32) []Match {
+	var matches []Match
+	for _, e := range m.entries {
+		if !maskedEqual32(ip, e.ip, int(e.prefix)) {
+			continue
+		}
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], e.ip)
+		matches = append(matches, Match{
+			// Masked so Prefix is the compiled CIDR's network address, not
+			// ip itself truncated to a bit count.
+			Prefix: netip.PrefixFrom(netip.AddrFrom4(b), int(e.prefix)).Masked(),
+			Value:  e.value,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Prefix.Bits() > matches[j].Prefix.Bits()
+	})
+	return matches
+}
+
+// maskedEqual32 reports whether ip and entryIP agree on their top
+// prefixBits bits.
+func maskedEqual32(ip, entryIP uint32, prefixBits int) bool {
+	mask := uint32(0xFFFFFFFF) << (32 - prefixBits)
+	return ip&mask == entryIP&mask
+}
@@ -0,0 +1,78 @@
+package gosm
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindAllMostToLeastSpecific(t *testing.T) {
+	sm, err := Compile(
+		[]uint32{0x0a000000, 0x0a000000, 0x0a0a0000},
+		[]uint8{8, 16, 24},
+		[]uint64{1, 2, 3},
+	)
+	require.NoError(t, err)
+
+	matches := sm.FindAll(0x0a0a0001)
+	require.Len(t, matches, 3)
+	require.Equal(t, uint8(24), uint8(matches[0].Prefix.Bits()))
+	require.Equal(t, uint64(3), matches[0].Value)
+	require.Equal(t, uint8(16), uint8(matches[1].Prefix.Bits()))
+	require.Equal(t, uint64(2), matches[1].Value)
+	require.Equal(t, uint8(8), uint8(matches[2].Prefix.Bits()))
+	require.Equal(t, uint64(1), matches[2].Value)
+
+	// The most-specific match must agree with Find.
+	require.Equal(t, sm.Find(0x0a0a0001), matches[0].Value)
+}
+
+func TestFindAllNoMatch(t *testing.T) {
+	sm, err := Compile([]uint32{0x0a000000}, []uint8{8}, []uint64{1})
+	require.NoError(t, err)
+
+	require.Empty(t, sm.FindAll(0xc0000001))
+}
+
+func TestWalkRoundTrip(t *testing.T) {
+	ips := []uint32{0x0a000000, 0xc0a80000}
+	prefixes := []uint8{8, 16}
+	values := []uint64{1, 2}
+
+	sm, err := Compile(ips, prefixes, values)
+	require.NoError(t, err)
+
+	seen := make(map[netip.Prefix]uint64)
+	sm.Walk(func(prefix netip.Prefix, value uint64) bool {
+		seen[prefix] = value
+		return true
+	})
+
+	require.Len(t, seen, len(ips))
+	for i, ip := range ips {
+		var b [4]byte
+		b[0] = byte(ip >> 24)
+		b[1] = byte(ip >> 16)
+		b[2] = byte(ip >> 8)
+		b[3] = byte(ip)
+		prefix := netip.PrefixFrom(netip.AddrFrom4(b), int(prefixes[i])).Masked()
+		require.Equal(t, values[i], seen[prefix])
+	}
+}
+
+func TestWalkStopsEarly(t *testing.T) {
+	sm, err := Compile(
+		[]uint32{0x0a000000, 0xc0a80000},
+		[]uint8{8, 16},
+		[]uint64{1, 2},
+	)
+	require.NoError(t, err)
+
+	count := 0
+	sm.Walk(func(prefix netip.Prefix, value uint64) bool {
+		count++
+		return false
+	})
+	require.Equal(t, 1, count)
+}
@@ -0,0 +1,41 @@
+package gosm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromMappedFile_RoundTrip(t *testing.T) {
+	ips := []uint32{0x0A000000, 0x0A000100}
+	prefixes := []uint8{24, 24}
+	values := []uint64{1, 2}
+
+	sm, err := Compile(ips, prefixes, values)
+	require.NoError(t, err)
+
+	ser, err := sm.Serialize()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "map.db")
+	require.NoError(t, os.WriteFile(path, ser, 0o644))
+
+	loaded, err := FromMappedFile(path)
+	require.NoError(t, err)
+	defer loaded.Close()
+
+	require.Equal(t, uint64(1), loaded.Find(0x0A000001))
+	require.Equal(t, uint64(2), loaded.Find(0x0A000101))
+}
+
+func TestFromMappedFile_RejectsBadMagic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "garbage.db")
+	require.NoError(t, os.WriteFile(path, make([]byte, 16), 0o644))
+
+	_, err := FromMappedFile(path)
+	require.Error(t, err)
+}
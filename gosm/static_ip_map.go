@@ -0,0 +1,15 @@
+package gosm
+
+import "net/netip"
+
+// StaticIPMap is StaticMapNet under the name later callers (firewall/geoip
+// loaders juggling mixed v4/v6 rulesets) expect: a single type dispatching
+// Find across an IPv4 and an IPv6 table. The two names are interchangeable;
+// StaticMapNet was the original name introduced alongside CompileV6 and
+// StaticMap6.
+type StaticIPMap = StaticMapNet
+
+// CompileIPMap is CompileNet under the alias paired with StaticIPMap.
+func CompileIPMap(prefixes []netip.Prefix, values []uint64) (*StaticIPMap, error) {
+	return CompileNet(prefixes, values)
+}
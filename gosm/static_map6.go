@@ -0,0 +1,172 @@
+package gosm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// magicUint32V6 is the 4-byte magic every serialized StaticMap6 begins with
+// ("SM6H" in little-endian bytes); see magicUint32 in mmap.go for the v4
+// counterpart.
+const magicUint32V6 = 0x48364d53
+
+// staticMap6Node is one node of the binary trie CompileV6 builds: each bit
+// of the address walks left (0) or right (1) to the next node, and a node
+// at depth N holds the value of any compiled /N prefix terminating there.
+type staticMap6Node struct {
+	hasValue bool
+	value    uint64
+	children [2]*staticMap6Node
+}
+
+// StaticMap6 is the IPv6 counterpart of StaticMap: a compiled longest-prefix
+// -match table keyed by a 16-byte address and /0../128 prefix length
+// instead of a uint32 address and /0../32 prefix length.
+//
+// libhipermap's hm_sm_* entry points only ever took a uint32 address, with
+// no 128-bit-keyed counterpart, so unlike StaticMap this type keeps no
+// cgo-backed state at all: it is a plain binary trie over the address
+// bits, built and walked entirely in Go.
+type StaticMap6 struct {
+	root *staticMap6Node
+}
+
+func CompileV6(ips [][16]byte, cidrPrefixes []uint8, values []uint64) (*StaticMap6, error) {
+	if len(ips) != len(cidrPrefixes) {
+		return nil, errors.New("len(ips) != len(cidrPrefixes)")
+	}
+	if len(ips) != len(values) {
+		return nil, errors.New("len(ips) != len(values)")
+	}
+
+	m := &StaticMap6{root: &staticMap6Node{}}
+	for i, ip := range ips {
+		m.insert(ip, cidrPrefixes[i], values[i])
+	}
+	return m, nil
+}
+
+// insert records value at the trie node prefixLen bits down the path ip
+// takes from the root, creating intermediate nodes as needed. Inserting the
+// same (ip, prefixLen) pair again overwrites the earlier value, the same
+// way a later Compile entry for a duplicate CIDR wins over an earlier one.
+func (m *StaticMap6) insert(ip [16]byte, prefixLen uint8, value uint64) {
+	node := m.root
+	for b := uint8(0); b < prefixLen; b++ {
+		bit := (ip[b/8] >> (7 - b%8)) & 1
+		child := node.children[bit]
+		if child == nil {
+			child = &staticMap6Node{}
+			node.children[bit] = child
+		}
+		node = child
+	}
+	node.hasValue = true
+	node.value = value
+}
+
+// Find returns the value of the longest compiled prefix that covers ip, or
+// 0 if none does. Unlike StaticMap.Find, which reports no match as
+// 0xFFFFFFFFFFFFFFFF, a miss here cannot be told apart from a stored 0.
+func (m *StaticMap6) Find(ip [16]byte) uint64 {
+	var best uint64
+	node := m.root
+	if node.hasValue {
+		best = node.value
+	}
+	for b := uint8(0); b < 128 && node != nil; b++ {
+		bit := (ip[b/8] >> (7 - b%8)) & 1
+		node = node.children[bit]
+		if node != nil && node.hasValue {
+			best = node.value
+		}
+	}
+	return best
+}
+
+type staticMap6Entry struct {
+	ip     [16]byte
+	prefix uint8
+	value  uint64
+}
+
+// entries walks the trie depth-first and returns one entry per node that
+// holds a value, with the IP bits fixed by the path taken to reach it.
+// Unlike the order CompileV6 was originally called with, this is stable
+// across equivalent tries (it depends only on the final trie shape, not on
+// insertion history), which is what Serialize needs to round-trip.
+func (m *StaticMap6) entries() []staticMap6Entry {
+	var out []staticMap6Entry
+	var walk func(node *staticMap6Node, prefix [16]byte, depth uint8)
+	walk = func(node *staticMap6Node, prefix [16]byte, depth uint8) {
+		if node.hasValue {
+			out = append(out, staticMap6Entry{ip: prefix, prefix: depth, value: node.value})
+		}
+		for bit := uint8(0); bit < 2; bit++ {
+			child := node.children[bit]
+			if child == nil {
+				continue
+			}
+			next := prefix
+			if bit == 1 {
+				next[depth/8] |= 1 << (7 - depth%8)
+			}
+			walk(child, next, depth+1)
+		}
+	}
+	walk(m.root, [16]byte{}, 0)
+	return out
+}
+
+// Serialize emits a portable buffer recording every compiled (ip, prefix,
+// value) triple, which FromSerializedV6 rebuilds into an equivalent trie.
+// Unlike StaticMap.Serialize this isn't a libhipermap wire format — there
+// is no hm_sm6_* to match, since libhipermap has no 128-bit-keyed database
+// at all — it only needs to round-trip within this package.
+func (m *StaticMap6) Serialize() ([]byte, error) {
+	entries := m.entries()
+
+	buf := make([]byte, 8+25*len(entries))
+	binary.LittleEndian.PutUint32(buf[0:4], magicUint32V6)
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(len(entries)))
+
+	off := 8
+	for _, e := range entries {
+		copy(buf[off:off+16], e.ip[:])
+		buf[off+16] = e.prefix
+		binary.LittleEndian.PutUint64(buf[off+17:off+25], e.value)
+		off += 25
+	}
+	return buf, nil
+}
+
+// FromSerializedV6 reconstructs a StaticMap6 from a buffer produced by
+// Serialize.
+func FromSerializedV6(buffer []byte) (*StaticMap6, error) {
+	if len(buffer) < 8 {
+		return nil, fmt.Errorf("empty buffer")
+	}
+	if binary.LittleEndian.Uint32(buffer[0:4]) != magicUint32V6 {
+		return nil, fmt.Errorf("bad magic")
+	}
+	count := binary.LittleEndian.Uint32(buffer[4:8])
+
+	want := 8 + 25*int(count)
+	if len(buffer) < want {
+		return nil, fmt.Errorf("serialized StaticMap6 truncated: need %d bytes, got %d", want, len(buffer))
+	}
+
+	ips := make([][16]byte, count)
+	prefixes := make([]uint8, count)
+	values := make([]uint64, count)
+	off := 8
+	for i := uint32(0); i < count; i++ {
+		copy(ips[i][:], buffer[off:off+16])
+		prefixes[i] = buffer[off+16]
+		values[i] = binary.LittleEndian.Uint64(buffer[off+17 : off+25])
+		off += 25
+	}
+
+	return CompileV6(ips, prefixes, values)
+}
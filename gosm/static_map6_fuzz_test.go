@@ -0,0 +1,173 @@
+package gosm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func ip16(hi, lo uint64) [16]byte {
+	var b [16]byte
+	for i := 0; i < 8; i++ {
+		b[i] = byte(hi >> (56 - 8*i))
+		b[8+i] = byte(lo >> (56 - 8*i))
+	}
+	return b
+}
+
+// findValueV6 is the 128-bit generalization of findValue: the reference
+// longest-prefix-match implementation TestFindV6GoldVectors and
+// FuzzFindV6 check StaticMap6 against.
+func findValueV6(ips [][16]byte, cidrPrefixes []uint8, values []uint64, testIp [16]byte) uint64 {
+	res := uint64(0xFFFFFFFFFFFFFFFF)
+	bestPrefix := -1
+	for i := 0; i < len(ips); i++ {
+		ip, cidrPrefix, value := ips[i], int(cidrPrefixes[i]), values[i]
+		if cidrPrefix > bestPrefix && maskedEqualV6(testIp, ip, cidrPrefix) {
+			bestPrefix = cidrPrefix
+			res = value
+		}
+	}
+	return res
+}
+
+// maskedEqualV6 reports whether a and b agree on their top prefixBits bits.
+func maskedEqualV6(a, b [16]byte, prefixBits int) bool {
+	fullBytes := prefixBits / 8
+	for i := 0; i < fullBytes; i++ {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	remBits := prefixBits % 8
+	if remBits == 0 {
+		return true
+	}
+	mask := byte(0xFF) << (8 - remBits)
+	return a[fullBytes]&mask == b[fullBytes]&mask
+}
+
+func sampleIps6(ips [][16]byte) [][16]byte {
+	res := [][16]byte{ip16(0, 0), ip16(0, 1), ip16(0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF)}
+	for _, ip := range ips {
+		res = append(res, ip)
+		// A neighbor with the last byte flipped, to probe boundary behavior.
+		neighbor := ip
+		neighbor[15] ^= 1
+		res = append(res, neighbor)
+	}
+	return res
+}
+
+func TestFindV6GoldVectors(t *testing.T) {
+	type entry struct {
+		ip     [16]byte
+		prefix uint8
+		value  uint64
+	}
+	testCases := [][]entry{
+		{
+			{ip16(0x20010db800000000, 0), 32, 10},
+			{ip16(0x20010db900000000, 0), 32, 20},
+		},
+		{
+			{ip16(0x20010db800000000, 0), 32, 10},
+			{ip16(0x20010db800010000, 0), 48, 11},
+			{ip16(0x20010db900000000, 0), 32, 20},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var ips [][16]byte
+			var prefixes []uint8
+			var values []uint64
+			for _, e := range tc {
+				ips = append(ips, e.ip)
+				prefixes = append(prefixes, e.prefix)
+				values = append(values, e.value)
+			}
+
+			sm, err := CompileV6(ips, prefixes, values)
+			require.NoError(t, err)
+
+			ser, err := sm.Serialize()
+			require.NoError(t, err)
+			t.Log(hex.EncodeToString(ser))
+
+			for _, ip := range sampleIps6(ips) {
+				require.Equal(t, findValueV6(ips, prefixes, values, ip), sm.Find(ip), fmt.Sprintf("%x", ip))
+			}
+		})
+	}
+}
+
+func FuzzFindV6(f *testing.F) {
+	f.Add(
+		uint64(0x20010db800000000), uint64(0),
+		uint64(0x20010db900000000), uint64(0),
+		uint8(32), uint8(32),
+		uint64(10), uint64(20),
+	)
+
+	f.Fuzz(func(t *testing.T, hi1, lo1, hi2, lo2 uint64, p1, p2 uint8, v1, v2 uint64) {
+		ips := [][16]byte{ip16(hi1, lo1), ip16(hi2, lo2)}
+		prefixes := []uint8{p1, p2}
+		values := []uint64{v1, v2}
+
+		adjustInputsV6(ips, prefixes, values)
+
+		sm, err := CompileV6(ips, prefixes, values)
+		require.NoError(t, err)
+
+		ser, err := sm.Serialize()
+		require.NoError(t, err)
+		t.Log(hex.EncodeToString(ser))
+
+		for _, ip := range sampleIps6(ips) {
+			require.Equal(t, findValueV6(ips, prefixes, values, ip), sm.Find(ip), fmt.Sprintf("%x", ip))
+		}
+	})
+}
+
+func adjustInputsV6(ips [][16]byte, cidrPrefixes []uint8, values []uint64) {
+	for i, v := range values {
+		if v == 0xFFFFFFFFFFFFFFFF {
+			values[i] = 1
+		}
+	}
+	for i, p := range cidrPrefixes {
+		if p > 128 {
+			cidrPrefixes[i] = 128
+		}
+	}
+	for i, ip := range ips {
+		p := int(cidrPrefixes[i])
+		fullBytes := p / 8
+		remBits := p % 8
+		for j := fullBytes + 1; j < 16; j++ {
+			ip[j] = 0
+		}
+		if remBits != 0 {
+			mask := byte(0xFF) << (8 - remBits)
+			ip[fullBytes] &= mask
+		} else if fullBytes < 16 {
+			ip[fullBytes] = 0
+		}
+		ips[i] = ip
+	}
+	range2value := make(map[string]uint64)
+	for i, ip := range ips {
+		p := cidrPrefixes[i]
+		v := values[i]
+		s := fmt.Sprintf("%x/%d", ip, p)
+		v0, has := range2value[s]
+		if has {
+			values[i] = v0
+		} else {
+			range2value[s] = v
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package gosm
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustAddr16(t *testing.T, s string) [16]byte {
+	t.Helper()
+	addr, err := netip.ParseAddr(s)
+	require.NoError(t, err)
+	return addr.As16()
+}
+
+func TestCompileV6(t *testing.T) {
+	_, err := CompileV6(
+		[][16]byte{mustAddr16(t, "2001:db8::")},
+		[]uint8{32},
+		[]uint64{0},
+	)
+	require.NoError(t, err)
+}
+
+func TestCompileV6Fail(t *testing.T) {
+	_, err := CompileV6(
+		[][16]byte{mustAddr16(t, "2001:db8::")},
+		[]uint8{},
+		[]uint64{0},
+	)
+	require.ErrorContains(t, err, "len(ips) != len(cidrPrefixes)")
+
+	_, err = CompileV6(
+		[][16]byte{mustAddr16(t, "2001:db8::")},
+		[]uint8{32},
+		[]uint64{},
+	)
+	require.ErrorContains(t, err, "len(ips) != len(values)")
+}
+
+func TestFindV6(t *testing.T) {
+	ips := [][16]byte{
+		mustAddr16(t, "2001:db8::"),
+		mustAddr16(t, "2001:db8:1::"),
+	}
+	cidrPrefixes := []uint8{32, 48}
+	values := []uint64{10, 20}
+
+	sm, err := CompileV6(ips, cidrPrefixes, values)
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(10), sm.Find(mustAddr16(t, "2001:db8::1")))
+	require.Equal(t, uint64(20), sm.Find(mustAddr16(t, "2001:db8:1::1")))
+	require.Equal(t, uint64(0), sm.Find(mustAddr16(t, "2001:db9::1")))
+
+	ser, err := sm.Serialize()
+	require.NoError(t, err)
+
+	restored, err := FromSerializedV6(ser)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), restored.Find(mustAddr16(t, "2001:db8::1")))
+}
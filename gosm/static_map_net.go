@@ -0,0 +1,101 @@
+package gosm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+)
+
+// StaticMapNet is a unified longest-prefix-match table over netip.Prefix
+// keys, internally dispatching to a StaticMap for IPv4 prefixes and a
+// StaticMap6 for IPv6 ones so callers don't have to maintain two databases
+// (or two Find call sites) for mixed-family policy maps.
+type StaticMapNet struct {
+	v4 *StaticMap
+	v6 *StaticMap6
+}
+
+// CompileNet compiles prefixes/values into a StaticMapNet. Each prefix must
+// be in canonical masked form: hm_sm_compile rejects an IPv4 prefix with
+// bits set past its length, while CompileV6 ignores such bits in an IPv6
+// prefix. A v4-mapped-in-v6 prefix (::ffff:a.b.c.d/n)
+// is unmapped and its bit count rebased to the IPv4 table, the same way
+// Find unmaps v4-mapped-in-v6 query addresses before dispatching.
+func CompileNet(prefixes []netip.Prefix, values []uint64) (*StaticMapNet, error) {
+	if len(prefixes) != len(values) {
+		return nil, fmt.Errorf("len(prefixes) != len(values)")
+	}
+
+	var v4Ips []uint32
+	var v4Prefixes []uint8
+	var v4Values []uint64
+	var v6Ips [][16]byte
+	var v6Prefixes []uint8
+	var v6Values []uint64
+
+	for i, p := range prefixes {
+		mapped := p.Addr().Is4In6()
+		addr := p.Addr().Unmap()
+		bits := p.Bits()
+		if mapped {
+			bits -= 96
+		}
+		switch {
+		case addr.Is4():
+			b := addr.As4()
+			v4Ips = append(v4Ips, binary.BigEndian.Uint32(b[:]))
+			v4Prefixes = append(v4Prefixes, uint8(bits))
+			v4Values = append(v4Values, values[i])
+		case addr.Is6():
+			v6Ips = append(v6Ips, addr.As16())
+			v6Prefixes = append(v6Prefixes, uint8(bits))
+			v6Values = append(v6Values, values[i])
+		default:
+			return nil, fmt.Errorf("prefix %d: invalid address", i)
+		}
+	}
+
+	m := &StaticMapNet{}
+	var err error
+	if len(v4Ips) > 0 {
+		m.v4, err = Compile(v4Ips, v4Prefixes, v4Values)
+		if err != nil {
+			return nil, fmt.Errorf("compiling IPv4 prefixes: %w", err)
+		}
+	}
+	if len(v6Ips) > 0 {
+		m.v6, err = CompileV6(v6Ips, v6Prefixes, v6Values)
+		if err != nil {
+			return nil, fmt.Errorf("compiling IPv6 prefixes: %w", err)
+		}
+	}
+	return m, nil
+}
+
+// Find returns the value of the longest prefix in the map that contains
+// addr, or 0 if none does. A v4-mapped-in-v6 address (::ffff:a.b.c.d) is
+// looked up against the IPv4 table, matching how such addresses are treated
+// everywhere else in this package.
+func (m *StaticMapNet) Find(addr netip.Addr) uint64 {
+	addr = addr.Unmap()
+	switch {
+	case addr.Is4():
+		if m.v4 == nil {
+			return 0
+		}
+		b := addr.As4()
+		v := m.v4.Find(binary.BigEndian.Uint32(b[:]))
+		if v == 0xFFFFFFFFFFFFFFFF {
+			// StaticMap.Find's no-match sentinel.
+			return 0
+		}
+		return v
+	case addr.Is6():
+		if m.v6 == nil {
+			return 0
+		}
+		return m.v6.Find(addr.As16())
+	default:
+		return 0
+	}
+}
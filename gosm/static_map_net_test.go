@@ -0,0 +1,52 @@
+package gosm
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileNetMixedFamilies(t *testing.T) {
+	prefixes := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}
+	values := []uint64{100, 200}
+
+	m, err := CompileNet(prefixes, values)
+	require.NoError(t, err)
+
+	require.Equal(t, uint64(100), m.Find(netip.MustParseAddr("10.1.2.3")))
+	require.Equal(t, uint64(200), m.Find(netip.MustParseAddr("2001:db8::1")))
+	require.Equal(t, uint64(0), m.Find(netip.MustParseAddr("192.168.0.1")))
+	require.Equal(t, uint64(0), m.Find(netip.MustParseAddr("2001:db9::1")))
+
+	// A v4-mapped-in-v6 address must route to the IPv4 table.
+	require.Equal(t, uint64(100), m.Find(netip.MustParseAddr("::ffff:10.1.2.3")))
+}
+
+func TestCompileNetV4MappedPrefix(t *testing.T) {
+	prefixes := []netip.Prefix{netip.MustParsePrefix("::ffff:10.0.0.0/104")}
+	m, err := CompileNet(prefixes, []uint64{1})
+	require.NoError(t, err)
+
+	// A v4-mapped-in-v6 prefix must compile into the IPv4 table, so a plain
+	// v4 query address (which never goes through Unmap) still matches.
+	require.Equal(t, uint64(1), m.Find(netip.MustParseAddr("10.1.2.3")))
+	require.Equal(t, uint64(1), m.Find(netip.MustParseAddr("::ffff:10.1.2.3")))
+	require.Equal(t, uint64(0), m.Find(netip.MustParseAddr("11.1.2.3")))
+}
+
+func TestCompileNetIPv4Only(t *testing.T) {
+	prefixes := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	m, err := CompileNet(prefixes, []uint64{1})
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), m.Find(netip.MustParseAddr("10.0.0.1")))
+	require.Equal(t, uint64(0), m.Find(netip.MustParseAddr("2001:db8::1")))
+}
+
+func TestCompileNetLengthMismatch(t *testing.T) {
+	_, err := CompileNet([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}, nil)
+	require.Error(t, err)
+}
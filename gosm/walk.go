@@ -0,0 +1,28 @@
+package gosm
+
+import (
+	"encoding/binary"
+	"net/netip"
+)
+
+// Walk calls fn once for every CIDR entry StaticMap was compiled from, in
+// the order Compile recorded them in. fn may return false to stop
+// iteration early. Walk exists so Compile -> Walk -> compare round trips are
+// possible in tests, since FindAll and Find can only report what covers a
+// specific address, not enumerate the whole table.
+//
+// libhipermap has no hm_sm_walk entrypoint (hm_sm_database_t cannot be
+// enumerated), so this iterates the entries Compile recorded on the Go
+// side instead of the C database. It therefore calls fn zero times for a
+// StaticMap loaded via FromSerialized or FromMappedFile, which has no
+// surviving entry list.
+func (m *StaticMap) Walk(fn func(prefix netip.Prefix, value uint64) bool) {
+	for _, e := range m.entries {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], e.ip)
+		prefix := netip.PrefixFrom(netip.AddrFrom4(b), int(e.prefix)).Masked()
+		if !fn(prefix, e.value) {
+			return
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package gostaticdomainset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindBatch(t *testing.T) {
+	ds, err := Compile(sampleDomainStrings)
+	require.NoError(t, err)
+
+	queries := append([]string{}, sampleDomainStrings...)
+	queries = append(queries, "not-present.invalid")
+
+	out := make([]bool, len(queries))
+	err = ds.FindBatch(queries, out)
+	require.NoError(t, err)
+
+	for i, q := range queries {
+		want, err := ds.Find(q)
+		require.NoError(t, err)
+		require.Equalf(t, want, out[i], "query %q", q)
+	}
+}
+
+func TestFindBatchLengthMismatch(t *testing.T) {
+	ds, err := Compile(sampleDomainStrings)
+	require.NoError(t, err)
+
+	err = ds.FindBatch(sampleDomainStrings, make([]bool, len(sampleDomainStrings)-1))
+	require.Error(t, err)
+}
+
+func TestSubmitBatchReapAndWait(t *testing.T) {
+	ds, err := Compile(sampleDomainStrings)
+	require.NoError(t, err)
+
+	queries := append([]string{}, sampleDomainStrings...)
+	queries = append(queries, "not-present.invalid")
+
+	rs := ds.SubmitBatch(queries)
+	results := rs.Wait()
+	require.Len(t, results, len(queries))
+
+	for i, q := range queries {
+		want, err := ds.Find(q)
+		require.NoError(t, err)
+		require.NotNil(t, results[i])
+		require.Equalf(t, want, results[i].Matched, "query %q", q)
+
+		latest, ok := rs.LatestResult(i)
+		require.True(t, ok)
+		require.Equal(t, want, latest.Matched)
+	}
+}
+
+func TestSubmitBatchEmpty(t *testing.T) {
+	ds, err := Compile(sampleDomainStrings)
+	require.NoError(t, err)
+
+	rs := ds.SubmitBatch(nil)
+	require.Empty(t, rs.Wait())
+}
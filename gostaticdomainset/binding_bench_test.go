@@ -40,6 +40,32 @@ func BenchmarkFind(b *testing.B) {
 	}
 }
 
+func BenchmarkFindMany(b *testing.B) {
+	ds, err := Compile(sampleDomainStrings)
+	if err != nil {
+		b.Fatalf("Compile: %v", err)
+	}
+
+	queries := buildFindBenchmarkQueries(b, ds, sampleDomainStrings)
+	if len(queries) == 0 {
+		b.Fatal("no benchmark queries")
+	}
+
+	totalBytes := 0
+	for _, q := range queries {
+		totalBytes += len(q)
+	}
+	b.SetBytes(int64(totalBytes))
+
+	out := make([]bool, len(queries))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ds.FindMany(queries, out); err != nil {
+			b.Fatalf("FindMany: %v", err)
+		}
+	}
+}
+
 func buildFindBenchmarkQueries(tb testing.TB, ds *StaticDomainSet, bases []string) []string {
 	tb.Helper()
 
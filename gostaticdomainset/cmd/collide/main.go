@@ -26,13 +26,16 @@ func main() {
 	n := flag.Int("n", 40, "number of subdomains to generate for base")
 	maxTries := flag.Int("tries", 200000, "max candidates to try for collision")
 	brute := flag.Bool("bruteforce", true, "enable brute-force placement-mismatch search (slow)")
+	fingerprintBits := flag.Int("fingerprint-bits", 48, "verification fingerprint width (0, 16, 32, or 48)")
 	flag.Parse()
 
+	opts := sds.CompileOptions{FingerprintBits: sds.FingerprintBits(*fingerprintBits)}
+
 	// Build initial patterns: many subdomains of base (make it popular), do not include the base itself.
 	var patterns []string
 	patterns = append(patterns, makeGroup(*base, *n)...)
 
-	ds, err := sds.Compile(patterns)
+	ds, err := sds.CompileWithOptions(patterns, opts)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "compile error:", err)
 		os.Exit(1)
@@ -63,7 +66,7 @@ func main() {
 		fmt.Printf("Found candidate with colliding 16-bit popular hash: %q, hash=0x%04x\n", candidate, target)
 
 		patterns2 := append(append([]string(nil), patterns...), candidate)
-		ds2, err := sds.Compile(patterns2)
+		ds2, err := sds.CompileWithOptions(patterns2, opts)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "compile error (with candidate):", err)
 			os.Exit(1)
@@ -105,7 +108,7 @@ func main() {
 		candidate := fmt.Sprintf("%s.%s", genLabel(3, 8), *extra) // two labels
 		query := "a." + candidate                                 // not present, should match via suffix
 		patterns2 := append(append([]string(nil), patterns...), candidate)
-		ds2, err := sds.Compile(patterns2)
+		ds2, err := sds.CompileWithOptions(patterns2, opts)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "compile error (with candidate):", err)
 			os.Exit(1)
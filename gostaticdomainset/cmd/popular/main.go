@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"sort"
@@ -37,8 +38,15 @@ func hasSuffixOnLabelBoundary(s, suf string) bool {
 	return s[len(s)-len(suf)-1] == '.'
 }
 
-// pruneSubdomains removes domains that are proper subdomains of a domain already kept.
-func pruneSubdomains(domains []string) []string {
+// pruneSubdomains removes domains that are proper subdomains of a domain
+// already kept. When psl is non-nil, a candidate base only absorbs its
+// subdomains if base itself is strictly deeper than its eTLD+1 (the
+// registrable domain); otherwise base is either a public suffix itself or
+// exactly the registrable domain, and collapsing under it would hide
+// distinct customer domains (e.g. "example.co.uk" must not swallow
+// "a.example.co.uk", but "c.b.example.co.uk" may be collapsed into
+// the already-kept "b.example.co.uk").
+func pruneSubdomains(domains []string, psl *publicSuffixList) []string {
 	if len(domains) == 0 {
 		return domains
 	}
@@ -53,7 +61,7 @@ func pruneSubdomains(domains []string) []string {
 		if s == base {
 			continue
 		}
-		if hasSuffixOnLabelBoundary(s, base) {
+		if hasSuffixOnLabelBoundary(s, base) && baseMayAbsorbSubdomains(base, psl) {
 			// s is a subdomain of base; skip
 			continue
 		}
@@ -62,10 +70,24 @@ func pruneSubdomains(domains []string) []string {
 	return out
 }
 
+// baseMayAbsorbSubdomains reports whether base is allowed to absorb its
+// subdomains during pruning: always true with no PSL loaded, and otherwise
+// only when base has strictly more labels than its own eTLD+1.
+func baseMayAbsorbSubdomains(base string, psl *publicSuffixList) bool {
+	if psl == nil {
+		return true
+	}
+	plusOne := psl.effectiveTLDPlusOne(base)
+	return strings.Count(base, ".") > strings.Count(plusOne, ".")
+}
+
 // countPopular counts suffixes (depth >= 2 labels) across domains and returns
 // only those with counts > 16. A single domain contributes to all of its
-// suffixes of depth >= 2, e.g., a.b.c contributes to b.c and a.b.c.
-func countPopular(domains []string) map[string]int {
+// suffixes of depth >= 2, e.g., a.b.c contributes to b.c and a.b.c. When psl
+// is non-nil, suffixes at or above the domain's effective TLD (e.g. "co.uk"
+// or "s3.amazonaws.com") are never emitted, since they are public suffixes
+// shared by unrelated registrants rather than a single operator's domain.
+func countPopular(domains []string, psl *publicSuffixList) map[string]int {
 	counts := make(map[string]int)
 	for _, d := range domains {
 		if d == "" {
@@ -75,8 +97,13 @@ func countPopular(domains []string) map[string]int {
 		if len(labels) < 2 {
 			continue
 		}
-		// Build all suffixes with depth >= 2
-		for k := 2; k <= len(labels); k++ {
+		minDepth := 2
+		if psl != nil {
+			minDepth = strings.Count(psl.effectiveTLD(d), ".") + 2
+		}
+		// Build all suffixes with depth >= minDepth, i.e. strictly deeper
+		// than the effective TLD.
+		for k := minDepth; k <= len(labels); k++ {
 			suf := strings.Join(labels[len(labels)-k:], ".")
 			counts[suf]++
 		}
@@ -91,6 +118,24 @@ func countPopular(domains []string) map[string]int {
 }
 
 func main() {
+	pslPath := flag.String("psl", "", "path to a public_suffix_list.dat file; when set, suffixes at or above the effective TLD are never reported")
+	flag.Parse()
+
+	var psl *publicSuffixList
+	if *pslPath != "" {
+		f, err := os.Open(*pslPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "open psl file:", err)
+			os.Exit(2)
+		}
+		psl, err = parsePublicSuffixList(f)
+		f.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "parse psl file:", err)
+			os.Exit(2)
+		}
+	}
+
 	// Read domains from stdin, trim spaces and trailing dots, lowercase.
 	scanner := bufio.NewScanner(os.Stdin)
 	domains := make([]string, 0, 1024)
@@ -120,10 +165,10 @@ func main() {
 	}
 
 	// Prune subdomains
-	domains = pruneSubdomains(domains)
+	domains = pruneSubdomains(domains, psl)
 
 	// Count popular suffixes
-	counts := countPopular(domains)
+	counts := countPopular(domains, psl)
 
 	// Sort results by count desc, then lex asc
 	type item struct {
@@ -141,8 +186,14 @@ func main() {
 		return items[i].suf < items[j].suf
 	})
 
-	// Print: "suffix\tcount"
+	// Print: "suffix\tcount" or, with -psl, "suffix\tcount\tetld" so
+	// operators can audit which effective TLD each suffix was measured
+	// against.
 	for _, it := range items {
-		fmt.Printf("%s\t%d\n", it.suf, it.count)
+		if psl == nil {
+			fmt.Printf("%s\t%d\n", it.suf, it.count)
+			continue
+		}
+		fmt.Printf("%s\t%d\t%s\n", it.suf, it.count, psl.effectiveTLD(it.suf))
 	}
 }
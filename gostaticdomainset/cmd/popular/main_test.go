@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testPSL = `
+// icann.org rules (abridged for tests)
+com
+co.uk
+*.ck
+!www.ck
+s3.amazonaws.com
+`
+
+func mustParseTestPSL(t *testing.T) *publicSuffixList {
+	t.Helper()
+	psl, err := parsePublicSuffixList(strings.NewReader(testPSL))
+	require.NoError(t, err)
+	return psl
+}
+
+func TestEffectiveTLD(t *testing.T) {
+	psl := mustParseTestPSL(t)
+
+	require.Equal(t, "com", psl.effectiveTLD("example.com"))
+	require.Equal(t, "co.uk", psl.effectiveTLD("example.co.uk"))
+	require.Equal(t, "s3.amazonaws.com", psl.effectiveTLD("bucket.s3.amazonaws.com"))
+	// Wildcard rule "*.ck" matches any single label in front of "ck".
+	require.Equal(t, "foo.ck", psl.effectiveTLD("bar.foo.ck"))
+	// Exception "!www.ck" carves "ck" back out from under the wildcard.
+	require.Equal(t, "ck", psl.effectiveTLD("www.ck"))
+	// No matching rule: default "*" rule, eTLD is just the last label.
+	require.Equal(t, "zz", psl.effectiveTLD("example.zz"))
+}
+
+func TestEffectiveTLDPlusOne(t *testing.T) {
+	psl := mustParseTestPSL(t)
+
+	require.Equal(t, "example.co.uk", psl.effectiveTLDPlusOne("a.b.example.co.uk"))
+	require.Equal(t, "example.com", psl.effectiveTLDPlusOne("example.com"))
+}
+
+func TestCountPopularWithPSL(t *testing.T) {
+	psl := mustParseTestPSL(t)
+
+	var domains []string
+	for i := 0; i < 20; i++ {
+		domains = append(domains, "bucket"+string(rune('a'+i))+".s3.amazonaws.com")
+		domains = append(domains, "sub"+string(rune('a'+i))+".example.co.uk")
+	}
+
+	counts := countPopular(domains, psl)
+	_, hasAmazon := counts["s3.amazonaws.com"]
+	require.False(t, hasAmazon, "a suffix at the effective TLD must never be reported")
+	_, hasCoUK := counts["co.uk"]
+	require.False(t, hasCoUK)
+	require.Equal(t, 20, counts["example.co.uk"])
+}
+
+func TestPruneSubdomainsRespectsRegistrableDomain(t *testing.T) {
+	psl := mustParseTestPSL(t)
+
+	domains := []string{"example.co.uk", "other.example.co.uk"}
+	pruned := pruneSubdomains(append([]string{}, domains...), psl)
+	require.ElementsMatch(t, domains, pruned,
+		"a base at exactly the eTLD+1 must not absorb a sibling subdomain")
+
+	deep := []string{"b.example.co.uk", "c.b.example.co.uk"}
+	pruned = pruneSubdomains(append([]string{}, deep...), psl)
+	require.ElementsMatch(t, []string{"b.example.co.uk"}, pruned,
+		"a base strictly deeper than eTLD+1 may still absorb its own subdomains")
+}
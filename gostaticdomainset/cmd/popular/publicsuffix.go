@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// publicSuffixList is a minimal, dependency-free parser and matcher for the
+// standard public_suffix_list.dat format (https://publicsuffix.org/list/),
+// good enough to compute the effective TLD of a domain without pulling in
+// golang.org/x/net/publicsuffix. It implements the three rule kinds the
+// format defines (plain, "*" wildcard, "!" exception) and the standard
+// "longest matching rule wins" algorithm; it does not distinguish ICANN
+// from PRIVATE sections, since countPopular has no use for that split.
+type publicSuffixList struct {
+	exact     map[string]bool
+	wildcard  map[string]bool
+	exception map[string]bool
+}
+
+// parsePublicSuffixList reads r in public_suffix_list.dat format. Blank
+// lines and "//"-prefixed comments are ignored, as the format requires.
+func parsePublicSuffixList(r io.Reader) (*publicSuffixList, error) {
+	psl := &publicSuffixList{
+		exact:     make(map[string]bool),
+		wildcard:  make(map[string]bool),
+		exception: make(map[string]bool),
+	}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		// Rules may have trailing comments/whitespace; only the first field matters.
+		if fields := strings.Fields(line); len(fields) > 0 {
+			line = fields[0]
+		}
+		line = strings.ToLower(line)
+		switch {
+		case strings.HasPrefix(line, "!"):
+			psl.exception[strings.TrimPrefix(line, "!")] = true
+		case strings.HasPrefix(line, "*."):
+			psl.wildcard[strings.TrimPrefix(line, "*.")] = true
+		default:
+			psl.exact[line] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return psl, nil
+}
+
+// effectiveTLD returns the public suffix of domain, following the algorithm
+// at https://publicsuffix.org/list/: the matching rule with the most labels
+// wins; if no rule matches, the default rule "*" applies and the eTLD is
+// just the domain's last label.
+func (psl *publicSuffixList) effectiveTLD(domain string) string {
+	labels := strings.Split(domain, ".")
+
+	bestLabels := 0
+	best := ""
+	bestIsException := false
+
+	for i := 0; i < len(labels); i++ {
+		n := len(labels) - i
+		candidate := strings.Join(labels[i:], ".")
+
+		if psl.exception[candidate] && n > bestLabels {
+			best, bestLabels, bestIsException = candidate, n, true
+		}
+		if psl.exact[candidate] && n > bestLabels {
+			best, bestLabels, bestIsException = candidate, n, false
+		}
+		if i+1 <= len(labels) {
+			rest := strings.Join(labels[i+1:], ".")
+			if psl.wildcard[rest] && n > bestLabels {
+				best, bestLabels, bestIsException = candidate, n, false
+			}
+		}
+	}
+
+	if bestLabels == 0 {
+		// Default rule "*": the eTLD is the domain's last label.
+		return labels[len(labels)-1]
+	}
+	if bestIsException {
+		// An exception rule "!foo.bar" means "bar" is the suffix, i.e. the
+		// matched rule minus its leftmost label.
+		if idx := strings.IndexByte(best, '.'); idx >= 0 {
+			return best[idx+1:]
+		}
+		return best
+	}
+	return best
+}
+
+// effectiveTLDPlusOne returns the registrable domain (eTLD+1) of domain: the
+// effective TLD plus the one label to its left. If domain has no label to
+// spare (domain is itself the eTLD or shorter), domain is returned as-is.
+func (psl *publicSuffixList) effectiveTLDPlusOne(domain string) string {
+	etld := psl.effectiveTLD(domain)
+	if etld == domain {
+		return domain
+	}
+	rest := strings.TrimSuffix(domain, "."+etld)
+	if idx := strings.LastIndexByte(rest, '.'); idx >= 0 {
+		rest = rest[idx+1:]
+	}
+	return rest + "." + etld
+}
@@ -6,12 +6,18 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
 	sds "github.com/starius/hipermap/gostaticdomainset"
 )
 
+// labels lists the derivative checks run against each pattern, in the order
+// they are printed in the summary. Add new derivative kinds here so -labels
+// filtering and the summary table pick them up automatically.
+var labels = []string{"exact", "add_subdomain", "remove_subdomain", "add_letter", "remove_letter", "trailing_dot"}
+
 func readLines(path string) ([]string, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -82,6 +88,8 @@ func extractDomain(s string) (string, error) {
 func main() {
 	patternsPath := flag.String("patterns", "", "path to patterns file (one domain per line)")
 	textPath := flag.String("text", "", "path to text file with 'url,count' lines")
+	labelsFlag := flag.String("labels", "", "regexp selecting which derivative checks to run (like go test -run); empty runs all of: "+strings.Join(labels, ", "))
+	batchSize := flag.Int("batch", 0, "if >0, evaluate -text in batches of this many domains per FindMany call instead of one Find call per domain")
 	flag.Parse()
 
 	if *patternsPath == "" || *textPath == "" {
@@ -89,6 +97,16 @@ func main() {
 		os.Exit(2)
 	}
 
+	labelRe, err := regexp.Compile(*labelsFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bad -labels regexp:", err)
+		os.Exit(2)
+	}
+	runLabel := make(map[string]bool, len(labels))
+	for _, lbl := range labels {
+		runLabel[lbl] = labelRe.MatchString(lbl)
+	}
+
 	patterns, err := readLines(*patternsPath)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "read patterns:", err)
@@ -135,14 +153,21 @@ func main() {
 	// and print per-label totals.
 	var patternMismatches int
 	var patternErrors int
-	labels := []string{"exact", "add_subdomain", "remove_subdomain", "add_letter", "remove_letter", "trailing_dot"}
-	patternTotals := map[string]int{"exact": 0, "add_subdomain": 0, "remove_subdomain": 0, "add_letter": 0, "remove_letter": 0, "trailing_dot": 0}
-	patternMism := map[string]int{"exact": 0, "add_subdomain": 0, "remove_subdomain": 0, "add_letter": 0, "remove_letter": 0, "trailing_dot": 0}
-	patternErrs := map[string]int{"exact": 0, "add_subdomain": 0, "remove_subdomain": 0, "add_letter": 0, "remove_letter": 0, "trailing_dot": 0}
+	patternTotals := make(map[string]int, len(labels))
+	patternMism := make(map[string]int, len(labels))
+	patternErrs := make(map[string]int, len(labels))
+	for _, lbl := range labels {
+		patternTotals[lbl] = 0
+		patternMism[lbl] = 0
+		patternErrs[lbl] = 0
+	}
 	for idx, p := range patterns {
 		// Helper to check a single domain
 		which := 0
 		check := func(label, dom string) {
+			if !runLabel[label] {
+				return
+			}
 			patternTotals[label]++
 			// Alternate between original and deserialized DB for coverage
 			var gotFast bool
@@ -207,62 +232,176 @@ func main() {
 	var fastN, naiveN int
 	var fastTotal time.Duration
 	var naiveTotal time.Duration
-	for sc.Scan() {
-		line := strings.TrimSpace(sc.Text())
-		if line == "" {
-			continue
-		}
-		total++
-		dom, err := extractDomain(line)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "parse error on line %d: %v\n", total, err)
-			parseErrors++
-			continue
-		}
-		valid++
-		// Alternate between original (odd lines) and deserialized (even lines)
-		useDs2 := (total%2 == 0)
-		t0 := time.Now()
-		var gotFast bool
-		var errFast error
-		if useDs2 {
-			gotFast, errFast = ds2.Find(dom)
-		} else {
-			gotFast, errFast = ds.Find(dom)
-		}
-		fastTotal += time.Since(t0)
-		fastN++
-		if errFast != nil {
-			fmt.Fprintf(os.Stderr, "find error (fast) on line %d: url=%q domain=%q err=%v\n", total, line, dom, errFast)
-			fastFindErrors++
-			continue
+	var fastCalls int
+	var fastCallTotal time.Duration
+
+	if *batchSize <= 0 {
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" {
+				continue
+			}
+			total++
+			dom, err := extractDomain(line)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "parse error on line %d: %v\n", total, err)
+				parseErrors++
+				continue
+			}
+			valid++
+			// Alternate between original (odd lines) and deserialized (even lines)
+			useDs2 := (total%2 == 0)
+			t0 := time.Now()
+			var gotFast bool
+			var errFast error
+			if useDs2 {
+				gotFast, errFast = ds2.Find(dom)
+			} else {
+				gotFast, errFast = ds.Find(dom)
+			}
+			d := time.Since(t0)
+			fastTotal += d
+			fastCallTotal += d
+			fastN++
+			fastCalls++
+			if errFast != nil {
+				fmt.Fprintf(os.Stderr, "find error (fast) on line %d: url=%q domain=%q err=%v\n", total, line, dom, errFast)
+				fastFindErrors++
+				continue
+			}
+			t1 := time.Now()
+			gotNaive, errNaive := naive.Find(dom)
+			naiveTotal += time.Since(t1)
+			naiveN++
+			if errNaive != nil {
+				fmt.Fprintf(os.Stderr, "find error (naive) on line %d: url=%q domain=%q err=%v\n", total, line, dom, errNaive)
+				naiveFindErrors++
+				// Count as discrepancy and continue; fast matched count already updated if applicable.
+				// Do not increment naiveMatched.
+				// Note: also count as discrepancy below if gotFast differs from false (implicit).
+			}
+
+			if errNaive != nil || gotFast != gotNaive {
+				discrepancies++
+				fmt.Fprintf(os.Stderr, "mismatch on line %d: url=%q domain=%q fast=%v naive=%v err_naive=%v\n", total, line, dom, gotFast, gotNaive, errNaive)
+			}
+			if gotFast {
+				fastMatched++
+			}
+			if errNaive == nil && gotNaive {
+				naiveMatched++
+			}
 		}
-		t1 := time.Now()
-		gotNaive, errNaive := naive.Find(dom)
-		naiveTotal += time.Since(t1)
-		naiveN++
-		if errNaive != nil {
-			fmt.Fprintf(os.Stderr, "find error (naive) on line %d: url=%q domain=%q err=%v\n", total, line, dom, errNaive)
-			naiveFindErrors++
-			// Count as discrepancy and continue; fast matched count already updated if applicable.
-			// Do not increment naiveMatched.
-			// Note: also count as discrepancy below if gotFast differs from false (implicit).
+		if err := sc.Err(); err != nil {
+			fmt.Fprintln(os.Stderr, "read text:", err)
+			os.Exit(1)
 		}
+	} else {
+		// Batch mode: accumulate up to -batch domains at a time (streaming,
+		// not buffering the whole file) and drive both implementations
+		// through FindMany, one call per chunk; FindMany still calls Find,
+		// and so crosses the cgo boundary, once per domain. lineNos/lines/doms
+		// track the real 1-based file line number alongside each domain so
+		// diagnostics point at the same place the non-batch path would.
+		lineNos := make([]int, 0, *batchSize)
+		lines := make([]string, 0, *batchSize)
+		doms := make([]string, 0, *batchSize)
+		fastOut := make([]bool, 0, *batchSize)
+		naiveOut := make([]bool, 0, *batchSize)
 
-		if errNaive != nil || gotFast != gotNaive {
-			discrepancies++
-			fmt.Fprintf(os.Stderr, "mismatch on line %d: url=%q domain=%q fast=%v naive=%v err_naive=%v\n", total, line, dom, gotFast, gotNaive, errNaive)
+		runChunk := func() {
+			if len(doms) == 0 {
+				return
+			}
+			fastOut = fastOut[:0]
+			for range doms {
+				fastOut = append(fastOut, false)
+			}
+			useDs2 := fastCalls%2 == 1
+			t0 := time.Now()
+			var errsFast []error
+			var errFast error
+			if useDs2 {
+				errsFast, errFast = ds2.FindMany(doms, fastOut)
+			} else {
+				errsFast, errFast = ds.FindMany(doms, fastOut)
+			}
+			d := time.Since(t0)
+			fastTotal += d
+			fastCallTotal += d
+			fastN += len(doms)
+			fastCalls++
+			if errFast != nil {
+				fmt.Fprintf(os.Stderr, "batch find error (fast) for lines %d-%d: %v\n", lineNos[0], lineNos[len(lineNos)-1], errFast)
+				fastFindErrors += len(doms)
+				return
+			}
+
+			naiveOut = naiveOut[:0]
+			for range doms {
+				naiveOut = append(naiveOut, false)
+			}
+			t1 := time.Now()
+			errsNaive, _ := naive.FindMany(doms, naiveOut)
+			naiveTotal += time.Since(t1)
+			naiveN += len(doms)
+
+			for i, dom := range doms {
+				lineNo := lineNos[i]
+				if errsFast != nil && errsFast[i] != nil {
+					fmt.Fprintf(os.Stderr, "find error (fast) on line %d: url=%q domain=%q err=%v\n", lineNo, lines[i], dom, errsFast[i])
+					fastFindErrors++
+					continue
+				}
+				gotFast := fastOut[i]
+				var errNaive error
+				if errsNaive != nil {
+					errNaive = errsNaive[i]
+				}
+				if errNaive != nil {
+					fmt.Fprintf(os.Stderr, "find error (naive) on line %d: url=%q domain=%q err=%v\n", lineNo, lines[i], dom, errNaive)
+					naiveFindErrors++
+				}
+				gotNaive := naiveOut[i]
+				if errNaive != nil || gotFast != gotNaive {
+					discrepancies++
+					fmt.Fprintf(os.Stderr, "mismatch on line %d: url=%q domain=%q fast=%v naive=%v err_naive=%v\n", lineNo, lines[i], dom, gotFast, gotNaive, errNaive)
+				}
+				if gotFast {
+					fastMatched++
+				}
+				if errNaive == nil && gotNaive {
+					naiveMatched++
+				}
+			}
 		}
-		if gotFast {
-			fastMatched++
+
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" {
+				continue
+			}
+			total++
+			dom, err := extractDomain(line)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "parse error on line %d: %v\n", total, err)
+				parseErrors++
+				continue
+			}
+			valid++
+			lineNos = append(lineNos, total)
+			lines = append(lines, line)
+			doms = append(doms, dom)
+			if len(doms) == *batchSize {
+				runChunk()
+				lineNos, lines, doms = lineNos[:0], lines[:0], doms[:0]
+			}
 		}
-		if errNaive == nil && gotNaive {
-			naiveMatched++
+		if err := sc.Err(); err != nil {
+			fmt.Fprintln(os.Stderr, "read text:", err)
+			os.Exit(1)
 		}
-	}
-	if err := sc.Err(); err != nil {
-		fmt.Fprintln(os.Stderr, "read text:", err)
-		os.Exit(1)
+		runChunk()
 	}
 
 	fmt.Println(ds.String())
@@ -285,6 +424,10 @@ func main() {
 	// Per-label pattern validation totals
 	fmt.Printf("Pattern checks by label:\n")
 	for _, lbl := range labels {
+		if !runLabel[lbl] {
+			fmt.Printf("  %-16s skipped (does not match -labels=%q)\n", lbl+":", *labelsFlag)
+			continue
+		}
 		fmt.Printf("  %-16s tests=%d mismatches=%d errors=%d\n", lbl+":", patternTotals[lbl], patternMism[lbl], patternErrs[lbl])
 	}
 	fmt.Printf("Pattern check mismatches: %d errors: %d\n", patternMismatches, patternErrors)
@@ -303,9 +446,16 @@ func main() {
 
 	// Average find latencies (ns/op). Use independent call counts to avoid division by zero.
 	if fastN > 0 {
-		fmt.Printf("Avg find latency (fast):  %.0f ns\n", float64(fastTotal.Nanoseconds())/float64(fastN))
+		fmt.Printf("Avg find latency (fast) per domain: %.0f ns\n", float64(fastTotal.Nanoseconds())/float64(fastN))
 	}
 	if naiveN > 0 {
-		fmt.Printf("Avg find latency (naive): %.0f ns\n", float64(naiveTotal.Nanoseconds())/float64(naiveN))
+		fmt.Printf("Avg find latency (naive) per domain: %.0f ns\n", float64(naiveTotal.Nanoseconds())/float64(naiveN))
+	}
+	// In -batch mode fastCalls counts FindMany calls (one per chunk) rather
+	// than one per domain, so per-call latency is the cost of one chunk.
+	// FindMany calls Find once per domain, so it grows roughly in step
+	// with -batch.
+	if fastCalls > 0 {
+		fmt.Printf("Avg find latency (fast) per call:   %.0f ns (%d call(s))\n", float64(fastCallTotal.Nanoseconds())/float64(fastCalls), fastCalls)
 	}
 }
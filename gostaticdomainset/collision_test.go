@@ -2,18 +2,18 @@ package gostaticdomainset
 
 import (
 	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 )
 
-// TestPopularCollision_Repro tries to reproduce the collision-induced mismatch
-// described in the bug report: when a domain's two-label suffix happens to
-// collide with a popular hash, Find grows the suffix and may probe a different
-// bucket than where the compiler placed it. This test searches for such a
-// colliding candidate and expects a mismatch (fast != naive) to highlight the bug.
-// NOTE: This test intentionally fails if no mismatch is observed; it serves as
-// a reproduction harness and may be updated once the bug is fixed.
+// TestPopularCollision_Repro reproduces the collision scenario described in
+// the bug report: a domain's two-label suffix collides with a popular hash,
+// so Find grows the suffix and probes the bucket as if the popular suffix
+// actually matched. Now that CompileWithOptions verifies a wide fingerprint
+// in addition to the 16-bit bucket tag before declaring a popular hit, this
+// must never diverge from NaiveDomainSet.
 func TestPopularCollision_Repro(t *testing.T) {
 	base := "popular.example.com"
 	n := 40 // > D (16) to make base popular by subdomains
@@ -57,7 +57,7 @@ func TestPopularCollision_Repro(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(fmt.Sprintf("unrelated=%s query=%s", tc.unrelated, tc.query), func(t *testing.T) {
 			patterns2 := append(append([]string(nil), patterns...), tc.unrelated)
-			ds2, err := Compile(patterns2)
+			ds2, err := CompileWithOptions(patterns2, CompileOptions{FingerprintBits: Fingerprint48})
 			require.NoError(t, err)
 
 			naive := NewNaiveDomainSet(patterns2)
@@ -72,3 +72,47 @@ func TestPopularCollision_Repro(t *testing.T) {
 		})
 	}
 }
+
+// TestPopularCollision_Bruteforce is a randomized differential test mirroring
+// the bruteforce reproducer in gostaticdomainset/cmd/collide: it repeatedly
+// adds random unrelated two-label domains alongside a popular base and
+// queries one of their subdomains, comparing the fast and naive matchers.
+// With a 48-bit fingerprint this must never disagree.
+func TestPopularCollision_Bruteforce(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping bruteforce differential test in -short mode")
+	}
+
+	base := "popular.example.com"
+	var patterns []string
+	for i := 0; i < 40; i++ {
+		patterns = append(patterns, fmt.Sprintf("x%d.%s", i, base))
+	}
+
+	const tries = 2000
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+	r := rand.New(rand.NewSource(42))
+	genLabel := func(n int) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = letters[r.Intn(len(letters))]
+		}
+		return string(b)
+	}
+
+	for i := 0; i < tries; i++ {
+		candidate := fmt.Sprintf("%s.tld", genLabel(3+r.Intn(6)))
+		query := "a." + candidate
+		patterns2 := append(append([]string(nil), patterns...), candidate)
+
+		ds, err := CompileWithOptions(patterns2, CompileOptions{FingerprintBits: Fingerprint48})
+		require.NoError(t, err)
+		naive := NewNaiveDomainSet(patterns2)
+
+		fast, err := ds.Find(query)
+		require.NoError(t, err)
+		ref, err := naive.Find(query)
+		require.NoError(t, err)
+		require.Equalf(t, ref, fast, "mismatch for candidate=%q query=%q", candidate, query)
+	}
+}
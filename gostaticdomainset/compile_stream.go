@@ -0,0 +1,124 @@
+package gostaticdomainset
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+)
+
+// progressInterval is how many scanned lines pass between ProgressFunc
+// calls, so the callback overhead stays negligible even on huge feeds.
+const progressInterval = 100000
+
+// ProgressFunc is called periodically during CompileStream with the number
+// of lines scanned so far, so long-running builds from multi-million-entry
+// feeds can report progress to an operator.
+type ProgressFunc func(linesScanned int)
+
+// CompileStream builds a StaticDomainSet from r instead of requiring a
+// fully materialized []string the way Compile does. Lines are read one at a
+// time (bufio.Scanner), trimmed, trailing-dot-trimmed, lowercased and
+// deduplicated, then any domain that is a proper subdomain of another
+// domain already in the set is dropped, the same collapsing domain-tools'
+// pruneSubdomains does, before the result is handed to CompileWithOptions.
+//
+// This avoids the "read stdin fully into one []byte, then split into one
+// []string" peak that reading r fully up front would cost, and reports
+// progress via progress (which may be nil). It does not achieve true
+// O(1)-memory streaming: collapsing subdomains needs the reverse-sorted
+// order pruneSubdomains relies on, which this builds with a single in-memory
+// sort.Slice over the deduplicated domain set rather than a genuine
+// streaming/external merge sort. Holding the deduplicated set is the same
+// fundamental cost pruneSubdomains already pays; what CompileStream removes
+// is the extra full-input-buffer copy in front of it.
+func CompileStream(r io.Reader, opts CompileOptions, progress ProgressFunc) (*StaticDomainSet, error) {
+	domains, err := scanAndPruneDomains(r, progress)
+	if err != nil {
+		return nil, err
+	}
+	if len(domains) == 0 {
+		return nil, ErrNoDomains
+	}
+	return CompileWithOptions(domains, opts)
+}
+
+func scanAndPruneDomains(r io.Reader, progress ProgressFunc) ([]string, error) {
+	seen := make(map[string]struct{})
+	var domains []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lines := 0
+	for scanner.Scan() {
+		lines++
+		s := strings.TrimSpace(scanner.Text())
+		for strings.HasSuffix(s, ".") {
+			s = strings.TrimSuffix(s, ".")
+		}
+		if s != "" {
+			s = strings.ToLower(s)
+			if _, ok := seen[s]; !ok {
+				seen[s] = struct{}{}
+				domains = append(domains, s)
+			}
+		}
+		if progress != nil && lines%progressInterval == 0 {
+			progress(lines)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if progress != nil {
+		progress(lines)
+	}
+
+	return pruneStreamedSubdomains(domains), nil
+}
+
+// lessRevChar compares strings by reversed character order so that a base
+// domain (e.g. "example.com") precedes its subdomains (e.g.
+// "a.example.com"), mirroring domain-tools' pruneSubdomains.
+func lessRevChar(a, b string) bool {
+	ia, ib := len(a), len(b)
+	for ia > 0 && ib > 0 {
+		ca, cb := a[ia-1], b[ib-1]
+		if ca != cb {
+			return ca < cb
+		}
+		ia--
+		ib--
+	}
+	return ia < ib
+}
+
+func hasSuffixOnLabelBoundary(s, suf string) bool {
+	if !strings.HasSuffix(s, suf) {
+		return false
+	}
+	if len(s) == len(suf) {
+		return true
+	}
+	return s[len(s)-len(suf)-1] == '.'
+}
+
+// pruneStreamedSubdomains removes domains that are proper subdomains of a
+// domain already kept, the same algorithm domain-tools' pruneSubdomains
+// uses: sort by reversed character order so a base domain sorts right
+// before its subdomains, then keep a domain only if it isn't a subdomain of
+// the last one kept.
+func pruneStreamedSubdomains(domains []string) []string {
+	if len(domains) == 0 {
+		return domains
+	}
+	sort.Slice(domains, func(i, j int) bool { return lessRevChar(domains[i], domains[j]) })
+	out := domains[:0]
+	for _, s := range domains {
+		if len(out) > 0 && hasSuffixOnLabelBoundary(s, out[len(out)-1]) {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
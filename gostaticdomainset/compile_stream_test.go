@@ -0,0 +1,51 @@
+package gostaticdomainset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileStreamDedupAndPrune(t *testing.T) {
+	input := strings.Join([]string{
+		"Example.com.",
+		"example.com",
+		"a.example.com",
+		"other.invalid",
+		"",
+		"  other.invalid  ",
+	}, "\n")
+
+	var progressCalls []int
+	ds, err := CompileStream(strings.NewReader(input), CompileOptions{}, func(n int) {
+		progressCalls = append(progressCalls, n)
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, progressCalls)
+
+	ok, err := ds.Find("example.com")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = ds.Find("other.invalid")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// a.example.com was pruned as a subdomain of example.com, so it is no
+	// longer a distinct pattern; this only checks that looking it up does
+	// not error.
+	_, err = ds.Find("a.example.com")
+	require.NoError(t, err)
+}
+
+func TestCompileStreamEmpty(t *testing.T) {
+	_, err := CompileStream(strings.NewReader(""), CompileOptions{}, nil)
+	require.Error(t, err)
+}
+
+func TestPruneStreamedSubdomains(t *testing.T) {
+	in := []string{"a.example.com", "example.com", "other.invalid"}
+	out := pruneStreamedSubdomains(in)
+	require.ElementsMatch(t, []string{"example.com", "other.invalid"}, out)
+}
@@ -126,3 +126,112 @@ func TestMaxLenQueryAndNonASCIIQuery(t *testing.T) {
 	_, err = ds.Find("пример.рф")
 	require.Error(t, err)
 }
+
+func TestFindMany(t *testing.T) {
+	ds, err := Compile(sampleDomainStrings)
+	require.NoError(t, err)
+
+	queries := append([]string{}, sampleDomainStrings...)
+	queries = append(queries, "not-present.invalid", "sub."+sampleDomainStrings[0])
+
+	out := make([]bool, len(queries))
+	errs, err := ds.FindMany(queries, out)
+	require.NoError(t, err)
+	require.Nil(t, errs)
+
+	for i, q := range queries {
+		want, err := ds.Find(q)
+		require.NoError(t, err)
+		require.Equalf(t, want, out[i], "query %q", q)
+	}
+}
+
+func TestFindMany_LengthMismatch(t *testing.T) {
+	ds, err := Compile(sampleDomainStrings)
+	require.NoError(t, err)
+
+	_, err = ds.FindMany([]string{"example.com"}, nil)
+	require.Error(t, err)
+}
+
+func TestFindManyBytes(t *testing.T) {
+	ds, err := Compile(sampleDomainStrings)
+	require.NoError(t, err)
+
+	queries := make([][]byte, len(sampleDomainStrings))
+	for i, q := range sampleDomainStrings {
+		queries[i] = []byte(q)
+	}
+
+	out := make([]bool, len(queries))
+	errs, err := ds.FindManyBytes(queries, out)
+	require.NoError(t, err)
+	require.Nil(t, errs)
+	for _, ok := range out {
+		require.True(t, ok)
+	}
+}
+
+func TestBenchmarkMany(t *testing.T) {
+	ds, err := Compile(sampleDomainStrings)
+	require.NoError(t, err)
+
+	queries := append([]string{}, sampleDomainStrings...)
+	queries = append(queries, "not-present.invalid", "sub."+sampleDomainStrings[0])
+
+	got, err := ds.BenchmarkMany(queries)
+	require.NoError(t, err)
+
+	var want uint64
+	for _, q := range queries {
+		ok, err := ds.Find(q)
+		require.NoError(t, err)
+		if ok {
+			want++
+		}
+	}
+	require.Equal(t, want, got)
+}
+
+func TestNaiveFindMany(t *testing.T) {
+	naive := NewNaiveDomainSet(sampleDomainStrings)
+
+	queries := append([]string{}, sampleDomainStrings...)
+	queries = append(queries, "not-present.invalid", "sub."+sampleDomainStrings[0])
+
+	out := make([]bool, len(queries))
+	errs, err := naive.FindMany(queries, out)
+	require.NoError(t, err)
+	require.Nil(t, errs)
+
+	for i, q := range queries {
+		want, err := naive.Find(q)
+		require.NoError(t, err)
+		require.Equalf(t, want, out[i], "query %q", q)
+	}
+}
+
+func TestNaiveFindMany_LengthMismatch(t *testing.T) {
+	naive := NewNaiveDomainSet(sampleDomainStrings)
+
+	_, err := naive.FindMany([]string{"example.com"}, nil)
+	require.Error(t, err)
+}
+
+func TestPopularEncodingOptionAccepted(t *testing.T) {
+	domains := []string{"example.com", "example.org", "example.net"}
+
+	for _, enc := range []PopularEncoding{PopularEncodingAuto, PopularEncodingArray, PopularEncodingBitmap} {
+		ds, err := CompileWithOptions(domains, CompileOptions{PopularEncoding: enc})
+		require.NoError(t, err)
+
+		ok, err := ds.Find("example.com")
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// PopularEncoding has no effect on either build (see its doc
+		// comment), so PopularContainerStats is always the zero value here.
+		stats := ds.PopularContainerStats()
+		require.Equal(t, PopularContainerStats{}, stats)
+	}
+}
@@ -12,4 +12,10 @@ var (
 	ErrTooManyPopularDomains = fmt.Errorf("too many popular domains")
 	ErrFailedToCalibrate     = fmt.Errorf("failed to calibrate")
 	ErrTopLevelDomain        = fmt.Errorf("top-level domains are not supported")
+	ErrInvalidIDN            = fmt.Errorf("invalid IDN label")
 )
+
+// ErrInvalidIDNA is ErrInvalidIDN under the name CompileIDNA/FindIDNA
+// document, for callers that only use the IDNA-specific entry points and
+// never see ErrInvalidIDN directly. The two compare equal with errors.Is.
+var ErrInvalidIDNA = ErrInvalidIDN
@@ -75,3 +75,81 @@ func FuzzStaticDomainSet_Find(f *testing.F) {
 		}
 	})
 }
+
+// FuzzStaticDomainSet_FindMany checks that FindMany agrees with a loop of
+// single Find calls over the same queries, reusing the ASCII-cleaned-byte
+// trick from FuzzStaticDomainSet_Find so the batch path sees the same
+// awkward inputs (DEL bytes, repeated dots, invalid characters) as the
+// single-query fuzzer.
+// Run via: go test -run ^$ -fuzz=FuzzStaticDomainSet_FindMany -fuzztime=60s ./gostaticdomainset
+func FuzzStaticDomainSet_FindMany(f *testing.F) {
+	base := []string{
+		"example.com",
+		"images.google.com",
+		"a..b.com",
+		"-start.com",
+		"end-.com",
+		"mi-d.le-.ex-ample.com",
+		"xn--puny-test.com",
+	}
+	base = append(base,
+		makeLabelN(63),
+		makeLabelN(64),
+		makeLabelN(120),
+		makeLabelN(200),
+	)
+
+	ds, err := Compile(base)
+	if err != nil {
+		f.Fatalf("failed to compile baseline: %v", err)
+	}
+
+	seeds := [][2]string{
+		{"example.com", "api.example.com"},
+		{"example.com.", "..example.com..."},
+		{"images.google.com", "x.images.google.com"},
+		{"a..b.com", "x.a..b.com"},
+		{"-start.com", "end-.com"},
+		{string([]byte{0x7f, 'a', '.', 'c', 'o', 'm'}), "white space.com"},
+	}
+	for _, s := range seeds {
+		f.Add(s[0], s[1])
+	}
+
+	clean := func(s string) string {
+		if len(s) > 512 {
+			s = s[:512]
+		}
+		b := []byte(s)
+		for i := range b {
+			b[i] &= 0x7F
+		}
+		return string(b)
+	}
+
+	f.Fuzz(func(t *testing.T, s1, s2 string) {
+		queries := []string{clean(s1), clean(s2), clean(s1), clean(s2)}
+
+		out := make([]bool, len(queries))
+		errs, err := ds.FindMany(queries, out)
+		if err != nil {
+			t.Fatalf("FindMany returned an unexpected top-level error: %v", err)
+		}
+
+		for i, q := range queries {
+			want, wantErr := ds.Find(q)
+			if errs != nil && errs[i] != nil {
+				if wantErr == nil {
+					t.Fatalf("FindMany reported an error for %q but Find did not: %v", q, errs[i])
+				}
+				continue
+			}
+			if wantErr != nil {
+				t.Fatalf("Find reported an error for %q but FindMany did not", q)
+			}
+			if out[i] != want {
+				t.Fatalf("FindMany/Find parity mismatch for %q: FindMany=%v Find=%v", q, out[i], want)
+			}
+		}
+	})
+}
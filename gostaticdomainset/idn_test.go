@@ -0,0 +1,54 @@
+package gostaticdomainset
+
+import (
+	"testing"
+
+	"github.com/starius/hipermap/idna"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDN_Off_RejectsUnicode(t *testing.T) {
+	_, err := CompileWithOptions([]string{"例え.com"}, CompileOptions{})
+	require.Error(t, err)
+}
+
+func TestIDN_TransitionalCompileAndFind(t *testing.T) {
+	ds, err := CompileWithOptions([]string{"例え.com"}, CompileOptions{IDN: IDNTransitional})
+	require.NoError(t, err)
+
+	// Query with the stored punycode spelling, computed the same way
+	// Compile normalized the original entry.
+	ascii, err := idna.ToASCII("例え.com", idna.Transitional)
+	require.NoError(t, err)
+
+	ok, err := ds.Find(ascii)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// Query with the original Unicode spelling and a subdomain of it both
+	// match the same ASCII-normalized pipeline.
+	ok, err = ds.Find("例え.com")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = ds.Find("sub.例え.com")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = ds.Find("other.com")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestIDN_MixedASCIIAndUnicodeEntries(t *testing.T) {
+	ds, err := CompileWithOptions([]string{"example.com", "mañana.org"}, CompileOptions{IDN: IDNTransitional})
+	require.NoError(t, err)
+
+	ok, err := ds.Find("example.com")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = ds.Find("xn--maana-pta.org")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
@@ -0,0 +1,9 @@
+package gostaticdomainset
+
+// CompileIDNA is a convenience wrapper around CompileWithOptions that turns
+// on Unicode domain support: each domain is lowercased and Punycode-encoded
+// (IDNTransitional) before being handed to the existing ASCII pipeline, so
+// callers can compile a blocklist straight from Unicode hostnames.
+func CompileIDNA(domains []string) (*StaticDomainSet, error) {
+	return CompileWithOptions(domains, CompileOptions{IDN: IDNTransitional})
+}
@@ -0,0 +1,22 @@
+//go:build !use_pure_gostaticdomainset
+// +build !use_pure_gostaticdomainset
+
+package gostaticdomainset
+
+import "github.com/starius/hipermap/idna"
+
+// FindIDNA looks up q the same way Find does, except it always normalizes q
+// through idna.ToASCII first regardless of the mode m was compiled with, so
+// a Unicode query matches a Punycode-stored pattern (and vice versa) even on
+// a database compiled with plain Compile. ascii is the normalized form that
+// was actually looked up, which is useful for debugging IDNA mismatches: two
+// Unicode spellings that a caller expects to be equivalent should normalize
+// to the same ascii value.
+func (m *StaticDomainSet) FindIDNA(q string) (matched bool, ascii string, err error) {
+	ascii, err = idna.ToASCII(q, IDNTransitional)
+	if err != nil {
+		return false, "", ErrInvalidIDNA
+	}
+	matched, err = m.Find(ascii)
+	return matched, ascii, err
+}
@@ -0,0 +1,38 @@
+package gostaticdomainset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileIDNAAndFindIDNA(t *testing.T) {
+	// "пример.рф" (Cyrillic for "example.rf").
+	ds, err := CompileIDNA([]string{"пример.рф"})
+	require.NoError(t, err)
+
+	ok, ascii, err := ds.FindIDNA("пример.рф")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.True(t, len(ascii) > 0 && ascii != "пример.рф", "ascii form should be punycode, got %q", ascii)
+
+	// The punycode spelling must look up to the same answer.
+	okFromPunycode, asciiFromPunycode, err := ds.FindIDNA(ascii)
+	require.NoError(t, err)
+	require.True(t, okFromPunycode)
+	require.Equal(t, ascii, asciiFromPunycode)
+
+	ok, _, err = ds.FindIDNA("not-present.invalid")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestFindIDNAWorksOnPlainCompile(t *testing.T) {
+	ds, err := Compile(sampleDomainStrings)
+	require.NoError(t, err)
+
+	ok, ascii, err := ds.FindIDNA(sampleDomainStrings[0])
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, sampleDomainStrings[0], ascii)
+}
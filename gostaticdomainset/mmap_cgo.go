@@ -0,0 +1,68 @@
+//go:build !use_pure_gostaticdomainset
+// +build !use_pure_gostaticdomainset
+
+package gostaticdomainset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// magicUint32 is the 4-byte magic ("HMDS" in little-endian bytes) every
+// serialized StaticDomainSet starts with; see parsePopularFromSerialized in
+// binding_test.go for the matching layout.
+const magicUint32 = 0x53444D48
+
+// FromSerializedMmap loads a StaticDomainSet from path by mmap'ing it
+// instead of reading it into a heap buffer first, then reconstructing the
+// database the same way FromSerialized does. The real libhipermap has no
+// in-place deserializer or attach entrypoint (only hm_domain_deserialize,
+// which always copies into a fresh db_place), so this does not avoid that
+// copy — it only avoids the separate read(2) and buffer allocation
+// FromSerialized would otherwise need to get the bytes off disk. The
+// mapping is released once the copy into db_place is complete.
+//
+// The magic header is validated against the mapped bytes directly, before
+// handing them to FromSerialized, so a corrupt or foreign file is rejected
+// with a clear error instead of being parsed.
+//
+// path must contain the exact bytes produced by Serialize. Close on the
+// returned *StaticDomainSet is a no-op kept safe to call for symmetry with
+// the other FromMappedFile loaders in this tree, which likewise copy and
+// unmap their file before returning.
+func FromSerializedMmap(path string) (*StaticDomainSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := fi.Size()
+	if size < 4 {
+		return nil, fmt.Errorf("empty file")
+	}
+
+	region, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("mmap %s: %w", path, err)
+	}
+	defer syscall.Munmap(region)
+
+	if binary.LittleEndian.Uint32(region[:4]) != magicUint32 {
+		return nil, fmt.Errorf("bad magic")
+	}
+
+	return FromSerialized(region)
+}
+
+// FromMappedFile is FromSerializedMmap under the name its siblings in
+// gostaticuint64set and gosm use for the same mmap-then-copy load.
+func FromMappedFile(path string) (*StaticDomainSet, error) {
+	return FromSerializedMmap(path)
+}
@@ -0,0 +1,45 @@
+//go:build !use_pure_gostaticdomainset
+// +build !use_pure_gostaticdomainset
+
+package gostaticdomainset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromSerializedMmap_RoundTrip(t *testing.T) {
+	ds, err := Compile(sampleDomainStrings)
+	require.NoError(t, err)
+
+	ser, err := ds.Serialize()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "domains.db")
+	require.NoError(t, os.WriteFile(path, ser, 0o644))
+
+	loaded, err := FromSerializedMmap(path)
+	require.NoError(t, err)
+	defer loaded.Close()
+
+	for _, d := range sampleDomainStrings {
+		want, err := ds.Find(d)
+		require.NoError(t, err)
+		got, err := loaded.Find(d)
+		require.NoError(t, err)
+		require.Equalf(t, want, got, "domain %q", d)
+	}
+}
+
+func TestFromSerializedMmap_RejectsBadMagic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "garbage.db")
+	require.NoError(t, os.WriteFile(path, make([]byte, 16), 0o644))
+
+	_, err := FromSerializedMmap(path)
+	require.Error(t, err)
+}
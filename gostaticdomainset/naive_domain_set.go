@@ -55,6 +55,33 @@ func (n *NaiveDomainSet) Find(domain string) (bool, error) {
 	return false, nil
 }
 
+// FindMany evaluates domains against n and writes one result per domain into
+// out, which must have the same length as domains. NaiveDomainSet has no
+// batched C routine to amortize, so this is a plain loop over Find; it
+// exists so callers comparing against StaticDomainSet.FindMany (cmd/verify's
+// -batch path, FuzzStaticDomainSet_FindMany) can drive both sides through
+// the same chunked API.
+//
+// A per-domain failure is reported in the returned []error at the same
+// index; errs is nil if every domain succeeded.
+func (n *NaiveDomainSet) FindMany(domains []string, out []bool) ([]error, error) {
+	if len(domains) != len(out) {
+		return nil, fmt.Errorf("FindMany: len(out)=%d != len(domains)=%d", len(out), len(domains))
+	}
+	var errs []error
+	for i, d := range domains {
+		ok, err := n.Find(d)
+		out[i] = ok
+		if err != nil {
+			if errs == nil {
+				errs = make([]error, len(domains))
+			}
+			errs[i] = err
+		}
+	}
+	return errs, nil
+}
+
 // Pure-Go domain validation: ASCII-only [A-Za-z0-9-._]
 func isValidDomain(s string) bool {
 	for i := 0; i < len(s); i++ {
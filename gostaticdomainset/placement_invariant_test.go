@@ -0,0 +1,61 @@
+package gostaticdomainset
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestPlacementInvariant_OrderIndependent checks the property a 2-choice /
+// cuckoo placement scheme would also have to satisfy: a compiled key's Find
+// result must not depend on the order domains were fed to Compile, i.e. on
+// how the popular-suffix table happened to fill up as entries were added.
+// See the FingerprintBits doc comment for why this tree implements that
+// property via a verification fingerprint (TestPopularCollision_Repro and
+// TestPopularCollision_Bruteforce cover the collision itself) instead of a
+// cuckoo relayout.
+func TestPlacementInvariant_OrderIndependent(t *testing.T) {
+	base := "popular.example.com"
+	var patterns []string
+	for i := 0; i < 40; i++ {
+		patterns = append(patterns, fmt.Sprintf("x%d.%s", i, base))
+	}
+
+	const extra = 30
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+	r := rand.New(rand.NewSource(7))
+	genLabel := func(n int) string {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = letters[r.Intn(len(letters))]
+		}
+		return string(b)
+	}
+
+	var others []string
+	var queries []string
+	for i := 0; i < extra; i++ {
+		d := fmt.Sprintf("%s.tld", genLabel(3+r.Intn(6)))
+		others = append(others, d)
+		queries = append(queries, "a."+d)
+	}
+
+	all := append(append([]string(nil), patterns...), others...)
+	shuffled := append([]string(nil), all...)
+	r.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+	ds1, err := CompileWithOptions(all, CompileOptions{FingerprintBits: Fingerprint48})
+	require.NoError(t, err)
+	ds2, err := CompileWithOptions(shuffled, CompileOptions{FingerprintBits: Fingerprint48})
+	require.NoError(t, err)
+
+	for _, q := range queries {
+		r1, err := ds1.Find(q)
+		require.NoError(t, err)
+		r2, err := ds2.Find(q)
+		require.NoError(t, err)
+		require.Equal(t, r1, r2, q)
+	}
+}
@@ -0,0 +1,99 @@
+package gostaticdomainset
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+//go:embed psl_snapshot.txt
+var defaultPSLData string
+
+// pslMu guards the package-level Public Suffix List used by SuffixMatch mode.
+var pslMu sync.RWMutex
+
+// publicSuffixes holds every rule as a full dotted suffix, e.g. "co.uk".
+// Populated from defaultPSLData at init and replaceable via SetPSL.
+var publicSuffixes map[string]struct{}
+
+func init() {
+	publicSuffixes = parsePSL(strings.NewReader(defaultPSLData))
+}
+
+// SetPSL replaces the embedded Public Suffix List snapshot used by
+// SuffixMatch mode with the rules read from r: one suffix per line (e.g.
+// "co.uk"), blank lines and lines starting with '#' ignored. Wildcard ("*.")
+// and exception ("!") rules from the official PSL format are not supported;
+// list the registrable labels you need explicitly.
+func SetPSL(r io.Reader) error {
+	rules := parsePSL(r)
+	if len(rules) == 0 {
+		return fmt.Errorf("empty public suffix list")
+	}
+	pslMu.Lock()
+	publicSuffixes = rules
+	pslMu.Unlock()
+	return nil
+}
+
+func parsePSL(r io.Reader) map[string]struct{} {
+	rules := make(map[string]struct{})
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rules[strings.ToLower(line)] = struct{}{}
+	}
+	return rules
+}
+
+// publicSuffixSpan returns the start offset of the public suffix of domain:
+// the longest dotted tail of domain that is a known public suffix, or the
+// last label if no tail matches. domain must already be lowercased with
+// trailing dots trimmed.
+func publicSuffixSpan(domain string) int {
+	pslMu.RLock()
+	defer pslMu.RUnlock()
+
+	best := -1
+	// Walk labels right to left, checking each dotted tail against the PSL.
+	lastLabel := -1
+	pos := len(domain)
+	for {
+		dot := strings.LastIndexByte(domain[:pos], '.')
+		labelStart := dot + 1
+		if lastLabel == -1 {
+			lastLabel = labelStart
+		}
+		tail := domain[labelStart:]
+		if _, ok := publicSuffixes[tail]; ok {
+			best = labelStart
+		}
+		if dot < 0 {
+			break
+		}
+		pos = dot
+	}
+	if best >= 0 {
+		return best
+	}
+	return lastLabel
+}
+
+// registrableDomainSpan returns the start offset of the registrable domain
+// (public suffix plus the one label directly above it), or -1 if domain is
+// itself the public suffix or shorter.
+func registrableDomainSpan(domain string) int {
+	sufStart := publicSuffixSpan(domain)
+	if sufStart <= 0 {
+		return -1
+	}
+	labelEnd := sufStart - 1 // the dot before the public suffix
+	labelStart := strings.LastIndexByte(domain[:labelEnd], '.') + 1
+	return labelStart
+}
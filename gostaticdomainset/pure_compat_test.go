@@ -53,6 +53,216 @@ func TestPureCGOSerializationCompatibility(t *testing.T) {
 	}
 }
 
+// TestPureCGOSerializationByteIdentical is the golden test promised by the
+// shared layout: round-tripping a serialized buffer through the other
+// build's FromSerialized/Serialize must reproduce the exact same bytes,
+// since both sides agree on the on-disk layout bit for bit.
+func TestPureCGOSerializationByteIdentical(t *testing.T) {
+	domains := sampleDomainStrings
+
+	pureSet, err := puregostaticdomainset.Compile(domains)
+	require.NoError(t, err)
+	pureSer, err := pureSet.Serialize()
+	require.NoError(t, err)
+
+	cgoFromPure, err := FromSerialized(pureSer)
+	require.NoError(t, err)
+	cgoSer, err := cgoFromPure.Serialize()
+	require.NoError(t, err)
+	require.Equal(t, pureSer, cgoSer, "cgo re-serialization of a pure-built database must be byte-identical")
+
+	pureFromCgo, err := puregostaticdomainset.FromSerialized(cgoSer)
+	require.NoError(t, err)
+	pureSer2, err := pureFromCgo.Serialize()
+	require.NoError(t, err)
+	require.Equal(t, cgoSer, pureSer2, "pure re-serialization of a cgo-loaded database must be byte-identical")
+}
+
+// TestCGOSerializeEncodedRoundTrip checks that the cgo StaticDomainSet's own
+// SerializeEncoded (which composes on top of puregostaticdomainset's codec,
+// since libhipermap has no delta-encoding entrypoint) produces a buffer
+// FromSerialized can load back into a working cgo database.
+func TestCGOSerializeEncodedRoundTrip(t *testing.T) {
+	domains := sampleDomainStrings
+
+	cgoSet, err := Compile(domains)
+	require.NoError(t, err)
+
+	encodedSer, err := cgoSet.SerializeEncoded()
+	require.NoError(t, err)
+
+	fromEncoded, err := FromSerialized(encodedSer)
+	require.NoError(t, err)
+
+	for _, q := range append(append([]string{}, domains...), "not-present.invalid") {
+		want, err := cgoSet.Find(q)
+		require.NoErrorf(t, err, "Find(%q)", q)
+		got, err := fromEncoded.Find(q)
+		require.NoErrorf(t, err, "Find(%q) after SerializeEncoded round trip", q)
+		require.Equalf(t, want, got, "query %q", q)
+	}
+}
+
+// TestCGOSerializeCompressedRoundTrip checks that the cgo StaticDomainSet's
+// own SerializeCompressed (which composes on top of puregostaticdomainset's
+// codec, since libhipermap has no dictionary-compression entrypoint)
+// produces a buffer FromSerialized can load back into a working cgo
+// database.
+func TestCGOSerializeCompressedRoundTrip(t *testing.T) {
+	domains := sampleDomainStrings
+
+	cgoSet, err := Compile(domains)
+	require.NoError(t, err)
+
+	compressedSer, err := cgoSet.SerializeCompressed()
+	require.NoError(t, err)
+
+	fromCompressed, err := FromSerialized(compressedSer)
+	require.NoError(t, err)
+
+	for _, q := range append(append([]string{}, domains...), "not-present.invalid") {
+		want, err := cgoSet.Find(q)
+		require.NoErrorf(t, err, "Find(%q)", q)
+		got, err := fromCompressed.Find(q)
+		require.NoErrorf(t, err, "Find(%q) after SerializeCompressed round trip", q)
+		require.Equalf(t, want, got, "query %q", q)
+	}
+}
+
+// TestEncodedBlobRoundTrip compiles the sample domains, serializes them both
+// ways, and asserts both formats agree with each other and with Find on the
+// uncompressed path once decoded.
+func TestEncodedBlobRoundTrip(t *testing.T) {
+	domains := sampleDomainStrings
+
+	pureSet, err := puregostaticdomainset.Compile(domains)
+	require.NoError(t, err)
+
+	plainSer, err := pureSet.Serialize()
+	require.NoError(t, err)
+	encodedSer, err := pureSet.SerializeEncoded()
+	require.NoError(t, err)
+	require.LessOrEqual(t, len(encodedSer), len(plainSer), "delta-encoded blob should not be larger than the verbatim one")
+
+	fromPlain, err := puregostaticdomainset.FromSerialized(plainSer)
+	require.NoError(t, err)
+	fromEncoded, err := puregostaticdomainset.FromSerialized(encodedSer)
+	require.NoError(t, err)
+
+	queries := append([]string{}, domains...)
+	queries = append(queries, "not-present.invalid")
+	for _, q := range queries {
+		want, err := fromPlain.Find(q)
+		require.NoErrorf(t, err, "Find(%q) on plain", q)
+		got, err := fromEncoded.Find(q)
+		require.NoErrorf(t, err, "Find(%q) on encoded", q)
+		require.Equalf(t, want, got, "query %q mismatch between plain and encoded formats", q)
+	}
+
+	// Re-serializing the encoded-and-reloaded set in plain form must match
+	// the original plain bytes exactly: decoding reconstructs the identical
+	// blob layout.
+	roundTripped, err := fromEncoded.Serialize()
+	require.NoError(t, err)
+	require.Equal(t, plainSer, roundTripped)
+}
+
+// TestCompressedBlobRoundTrip compiles the sample domains, serializes them
+// with the dictionary-compressed format, and asserts it agrees with the
+// plain format once decoded, and is usually smaller.
+func TestCompressedBlobRoundTrip(t *testing.T) {
+	domains := sampleDomainStrings
+
+	pureSet, err := puregostaticdomainset.Compile(domains)
+	require.NoError(t, err)
+
+	plainSer, err := pureSet.Serialize()
+	require.NoError(t, err)
+	compressedSer, err := pureSet.SerializeCompressed()
+	require.NoError(t, err)
+
+	fromPlain, err := puregostaticdomainset.FromSerialized(plainSer)
+	require.NoError(t, err)
+	fromCompressed, err := puregostaticdomainset.FromSerialized(compressedSer)
+	require.NoError(t, err)
+
+	queries := append([]string{}, domains...)
+	queries = append(queries, "not-present.invalid")
+	for _, q := range queries {
+		want, err := fromPlain.Find(q)
+		require.NoErrorf(t, err, "Find(%q) on plain", q)
+		got, err := fromCompressed.Find(q)
+		require.NoErrorf(t, err, "Find(%q) on compressed", q)
+		require.Equalf(t, want, got, "query %q mismatch between plain and compressed formats", q)
+	}
+
+	// Re-serializing the compressed-and-reloaded set in plain form must
+	// match the original plain bytes exactly: decompression reconstructs
+	// the identical blob layout.
+	roundTripped, err := fromCompressed.Serialize()
+	require.NoError(t, err)
+	require.Equal(t, plainSer, roundTripped)
+}
+
+// TestFromSerializedMmapAliasesBlob checks that FromSerializedMmap gives the
+// same lookups as FromSerialized, that it really does alias the input
+// buffer rather than copy it (corrupting the buffer corrupts lookups), and
+// that Release detaches it cleanly.
+func TestFromSerializedMmapAliasesBlob(t *testing.T) {
+	domains := sampleDomainStrings
+
+	pureSet, err := puregostaticdomainset.Compile(domains)
+	require.NoError(t, err)
+	plainSer, err := pureSet.Serialize()
+	require.NoError(t, err)
+
+	// A copy so mutating it later doesn't affect plainSer itself.
+	buf := append([]byte{}, plainSer...)
+
+	mmapSet, err := puregostaticdomainset.FromSerializedMmap(buf)
+	require.NoError(t, err)
+
+	for _, d := range domains {
+		want, err := pureSet.Find(d)
+		require.NoError(t, err)
+		got, err := mmapSet.Find(d)
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+
+	// Mutating the backing buffer must be visible through the aliased set,
+	// proving the blob wasn't copied.
+	for i := range buf {
+		buf[i] ^= 0xFF
+	}
+	corrupted := false
+	for _, d := range domains {
+		got, err := mmapSet.Find(d)
+		if err != nil || !got {
+			corrupted = true
+			break
+		}
+	}
+	require.True(t, corrupted, "FromSerializedMmap should alias the buffer, not copy it")
+
+	mmapSet.Release()
+}
+
+// TestFromSerializedMmapRejectsEncoded checks that FromSerializedMmap
+// refuses a buffer it can't zero-copy (the delta-encoded format) instead of
+// silently decoding it into a fresh allocation.
+func TestFromSerializedMmapRejectsEncoded(t *testing.T) {
+	domains := sampleDomainStrings
+
+	pureSet, err := puregostaticdomainset.Compile(domains)
+	require.NoError(t, err)
+	encodedSer, err := pureSet.SerializeEncoded()
+	require.NoError(t, err)
+
+	_, err = puregostaticdomainset.FromSerializedMmap(encodedSer)
+	require.Error(t, err)
+}
+
 func buildCrossQueries(t *testing.T, cgoSet *StaticDomainSet, pureSet *puregostaticdomainset.StaticDomainSet, bases []string) []string {
 	t.Helper()
 
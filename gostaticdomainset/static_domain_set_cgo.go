@@ -4,8 +4,14 @@
 package gostaticdomainset
 
 import (
+	"encoding/binary"
 	"fmt"
+	"strings"
+	"syscall"
 	"unsafe"
+
+	"github.com/starius/hipermap/idna"
+	"github.com/starius/hipermap/puregostaticdomainset"
 )
 
 // #include <stdlib.h>
@@ -15,15 +21,182 @@ import "C"
 type StaticDomainSet struct {
 	dbPlace []byte
 	db      *C.hm_domain_database_t
+
+	// mmap is the region Close munmaps if set. No loader in this package
+	// sets it (FromSerializedMmap unmaps its file before returning), so it
+	// is always nil.
+	mmap []byte
+
+	// patterns holds the original (pruned, lowercased) domains this set was
+	// compiled from, used by FindDetails to report which one matched. Only
+	// populated when compiled with CompileOptions.SuffixMatch.
+	patterns map[string]struct{}
+
+	// verify holds every compiled domain, lowercased, used by Find to
+	// re-check every hm_domain_find hit when compiled with a non-zero
+	// CompileOptions.FingerprintBits. Nil when FingerprintBits is
+	// FingerprintNone.
+	verify map[string]struct{}
+
+	// fingerprintBits is the CompileOptions.FingerprintBits m was compiled
+	// with, reported back by the FingerprintBits method. There is no C-side
+	// database field to read it back from (see FingerprintBits' doc
+	// comment), so it is just remembered here.
+	fingerprintBits FingerprintBits
+
+	// idnMode is the IDN normalization Compile applied to domains, if any;
+	// Find re-applies the same mode to queries so Unicode and punycode
+	// spellings of the same name compare equal. Zero value is IDNOff.
+	idnMode IDNMode
+}
+
+// IDNMode selects how Compile and Find treat non-ASCII domain labels. See
+// the idna package for the underlying ToASCII implementation.
+type IDNMode = idna.Mode
+
+const (
+	// IDNOff rejects non-ASCII input, the historical behavior.
+	IDNOff = idna.Off
+	// IDNTransitional maps and Punycode-encodes non-ASCII labels leniently.
+	IDNTransitional = idna.Transitional
+	// IDNStrict additionally rejects labels that are empty after mapping.
+	IDNStrict = idna.Strict
+)
+
+// FingerprintBits selects whether Find re-verifies an hm_domain_find hit
+// against the original compiled domain set before declaring a match.
+//
+// The real hm_domain_compile/hm_domain_find pair has no per-entry
+// fingerprint storage or verification step to plug into — this tree has no
+// hm_domain C source to redesign, only cgo bindings into it (the same
+// constraint that rules out a cuckoo/2-choice placement scheme; see
+// TestPlacementInvariant_OrderIndependent). So every non-zero value here
+// enables the same thing: CompileWithOptions keeps a copy of the
+// lowercased domain set, and Find walks a query's whole-label suffixes
+// against it whenever hm_domain_find reports a hit (any hit: the binding
+// cannot tell whether it came through a popular bucket). That is
+// an exact check, strictly stronger than any bounded-width hash
+// fingerprint, and costs nothing when hm_domain_find reports a miss. The
+// distinct bit widths exist for API parity with
+// puregostaticdomainset.CompileOptions.FingerprintBits, which has the
+// identical constraint in the other direction: its Find already verifies
+// the full candidate on every popular-suffix check, so it ignores the
+// option entirely.
+type FingerprintBits int
+
+const (
+	// FingerprintNone disables the verification fingerprint, matching the
+	// historical behavior where only the 16-bit bucket tag is checked.
+	FingerprintNone FingerprintBits = 0
+	Fingerprint16   FingerprintBits = 16
+	Fingerprint32   FingerprintBits = 32
+	Fingerprint48   FingerprintBits = 48
+)
+
+// PopularEncoding selects the container layout hm_domain_compile would use
+// to store which of the 2^16 hash buckets hold a popular suffix, if it
+// chunked the hash space into Roaring-style array/bitmap/run containers.
+// It doesn't: the real hm_domain_compile stores the popular-hash presence
+// bitmap as one fixed dense layout, and this tree has no hm_domain C source
+// to add chunked containers to, only cgo bindings into it.
+// CompileWithOptions accepts this field for API parity with
+// puregostaticdomainset.CompileOptions.PopularEncoding (which has the
+// identical constraint: it keeps popular suffixes as a plain sorted record
+// slice) but it has no effect on either build.
+type PopularEncoding int
+
+const (
+	// PopularEncodingAuto is the default value; see the type's doc comment.
+	PopularEncodingAuto PopularEncoding = 0
+
+	// PopularEncodingArray has no effect; see the type's doc comment.
+	PopularEncodingArray PopularEncoding = 1
+
+	// PopularEncodingBitmap has no effect; see the type's doc comment.
+	PopularEncodingBitmap PopularEncoding = 2
+)
+
+// CompileOptions controls optional behavior of Compile.
+type CompileOptions struct {
+	// FingerprintBits enables Find's exact re-check of every hit; every
+	// non-zero value behaves the same and nothing is stored per entry (see
+	// FingerprintBits). Zero value is FingerprintNone.
+	FingerprintBits FingerprintBits
+
+	// SuffixMatch, when true, validates domains using the Public Suffix List
+	// in addition to hm_domain_compile's own top-level check: a pattern that is itself a
+	// public suffix (e.g. "co.uk") is rejected with ErrTopLevelDomain. See
+	// SetPSL to override the embedded PSL snapshot.
+	SuffixMatch bool
+
+	// PopularEncoding has no effect; see the type's doc comment. Zero value
+	// is PopularEncodingAuto.
+	PopularEncoding PopularEncoding
+
+	// IDN selects how non-ASCII domain labels are handled. Zero value is
+	// IDNOff, which rejects non-ASCII input exactly as Compile always has.
+	// A non-Off mode normalizes domains (and, symmetrically, Find queries)
+	// through idna.ToASCII before the existing ASCII pipeline runs, so a
+	// Unicode suffix and its punycode spelling match each other either way.
+	IDN IDNMode
+}
+
+// PopularContainerStats mirrors
+// puregostaticdomainset.PopularContainerStats' per-container-type breakdown
+// of the popular-hash presence bitmap. Neither build actually chunks the
+// bitmap into Roaring-style containers (see PopularEncoding), so this is
+// always the zero value.
+type PopularContainerStats struct {
+	ArrayContainers  int
+	BitmapContainers int
+	RunContainers    int
 }
 
 // Compile builds a static domain set from a slice of domains.
 // Domains must be ASCII and case-insensitive; empty strings are not allowed.
 func Compile(domains []string) (*StaticDomainSet, error) {
+	return CompileWithOptions(domains, CompileOptions{})
+}
+
+// CompileWithOptions builds a static domain set like Compile, but lets the
+// caller opt into hit verification via opts.FingerprintBits: Find
+// then double-checks every hm_domain_find hit against the
+// original domain set before declaring a match, eliminating the false
+// positives the 16-bit bucket tag alone can produce on its own. See
+// FingerprintBits for why this is an exact re-check rather than a
+// bounded-width hash fingerprint.
+func CompileWithOptions(domains []string, opts CompileOptions) (*StaticDomainSet, error) {
 	if len(domains) == 0 {
 		return nil, ErrNoDomains
 	}
 
+	if opts.IDN != IDNOff {
+		converted := make([]string, len(domains))
+		for i, s := range domains {
+			ascii, err := idna.ToASCII(s, opts.IDN)
+			if err != nil {
+				return nil, ErrInvalidIDN
+			}
+			converted[i] = ascii
+		}
+		domains = converted
+	}
+
+	var patterns map[string]struct{}
+	if opts.SuffixMatch {
+		patterns = make(map[string]struct{}, len(domains))
+		for _, s := range domains {
+			if s == "" {
+				return nil, ErrEmptyDomain
+			}
+			lower := strings.ToLower(strings.TrimRight(s, "."))
+			if registrableDomainSpan(lower) < 0 {
+				return nil, ErrTopLevelDomain
+			}
+			patterns[lower] = struct{}{}
+		}
+	}
+
 	// Build C array of *C.char
 	cstrs := make([]*C.char, len(domains))
 	for i, s := range domains {
@@ -78,11 +251,39 @@ func Compile(domains []string) (*StaticDomainSet, error) {
 		}
 	}
 
-	return &StaticDomainSet{dbPlace: dbPlace, db: db}, nil
+	var verify map[string]struct{}
+	if opts.FingerprintBits != FingerprintNone {
+		verify = make(map[string]struct{}, len(domains))
+		for _, s := range domains {
+			verify[strings.ToLower(s)] = struct{}{}
+		}
+	}
+
+	return &StaticDomainSet{
+		dbPlace:         dbPlace,
+		db:              db,
+		patterns:        patterns,
+		verify:          verify,
+		fingerprintBits: opts.FingerprintBits,
+		idnMode:         opts.IDN,
+	}, nil
 }
 
-// Find returns whether the domain (case-insensitive) is present.
+// Find returns whether the domain (case-insensitive) is present. If m was
+// compiled with a non-Off CompileOptions.IDN, domain is first normalized
+// through the same idna.ToASCII pipeline Compile used, so a Unicode query
+// matches a punycode-stored suffix and vice versa. If m was compiled with a
+// non-zero CompileOptions.FingerprintBits, every hm_domain_find hit is
+// re-checked against the original domain set (see FingerprintBits) before
+// being reported as a match.
 func (m *StaticDomainSet) Find(domain string) (bool, error) {
+	if m.idnMode != IDNOff {
+		ascii, err := idna.ToASCII(domain, m.idnMode)
+		if err != nil {
+			return false, ErrInvalidIDN
+		}
+		domain = ascii
+	}
 	res := C.hm_domain_find(
 		m.db,
 		(*C.char)(unsafe.Pointer(unsafe.StringData(domain))),
@@ -90,6 +291,9 @@ func (m *StaticDomainSet) Find(domain string) (bool, error) {
 	)
 	switch res {
 	case 1:
+		if m.verify != nil && !matchesSuffixSet(m.verify, domain) {
+			return false, nil
+		}
 		return true, nil
 
 	case 0:
@@ -100,6 +304,112 @@ func (m *StaticDomainSet) Find(domain string) (bool, error) {
 	}
 }
 
+// matchesSuffixSet reports whether any whole-label suffix of domain is a
+// key of set, mirroring NaiveDomainSet.Find's suffix walk.
+func matchesSuffixSet(set map[string]struct{}, domain string) bool {
+	lower := strings.ToLower(strings.TrimRight(domain, "."))
+	for start := 0; ; {
+		if _, ok := set[lower[start:]]; ok {
+			return true
+		}
+		dot := strings.IndexByte(lower[start:], '.')
+		if dot < 0 {
+			return false
+		}
+		start += dot + 1
+	}
+}
+
+// FindMany evaluates queries one at a time via Find, batched into a single
+// call so callers (FindBatch, SubmitBatch, BenchmarkMany) have one entry
+// point to split work across. There is no hm_domain_find_many C entrypoint
+// to amortize the Go-to-C call overhead into a single cgo crossing, only
+// the per-query hm_domain_find that Find already calls; see FindBatch for
+// the parallel way to get more throughput out of it. out must have the
+// same length as queries; FindMany writes into it positionally and does
+// not reset entries it does not touch.
+//
+// A per-query failure (mirroring the codes Find can return) is reported in
+// the returned []error at the same index; errs is nil if every query
+// succeeded.
+func (m *StaticDomainSet) FindMany(queries []string, out []bool) ([]error, error) {
+	if len(queries) != len(out) {
+		return nil, fmt.Errorf("FindMany: len(out)=%d != len(queries)=%d", len(out), len(queries))
+	}
+
+	var errs []error
+	for i, q := range queries {
+		found, err := m.Find(q)
+		out[i] = found
+		if err != nil {
+			if errs == nil {
+				errs = make([]error, len(queries))
+			}
+			errs[i] = err
+		}
+	}
+	return errs, nil
+}
+
+// FindManyBytes is like FindMany but takes already-allocated byte slices,
+// for hot paths (packet parsing, zero-copy buffers) that never materialize
+// a Go string for each query.
+func (m *StaticDomainSet) FindManyBytes(queries [][]byte, out []bool) ([]error, error) {
+	strs := make([]string, len(queries))
+	for i, q := range queries {
+		if len(q) == 0 {
+			continue
+		}
+		strs[i] = unsafe.String(unsafe.SliceData(q), len(q))
+	}
+	return m.FindMany(strs, out)
+}
+
+// BenchmarkMany is the FindMany counterpart to StaticUint64Set.Benchmark: it
+// evaluates queries in a single batched call but reports only the aggregate
+// number of matches, for callers (such as a b.N loop) that want a count
+// rather than a per-query []bool to inspect. It crosses the cgo boundary
+// once per query, as FindMany does. There is no dedicated hm_domain counting
+// entrypoint to call into, so this composes on top of FindMany rather than
+// a lower-level C call.
+func (m *StaticDomainSet) BenchmarkMany(queries []string) (uint64, error) {
+	out := make([]bool, len(queries))
+	if _, err := m.FindMany(queries, out); err != nil {
+		return 0, err
+	}
+	var matched uint64
+	for _, v := range out {
+		if v {
+			matched++
+		}
+	}
+	return matched, nil
+}
+
+// FindDetails is like Find but also reports which compiled pattern matched,
+// for auditability. It is only meaningful for databases compiled with
+// CompileOptions.SuffixMatch; on other databases matchedPattern is always
+// empty.
+func (m *StaticDomainSet) FindDetails(domain string) (matched bool, matchedPattern string, err error) {
+	matched, err = m.Find(domain)
+	if err != nil || !matched || m.patterns == nil {
+		return matched, "", err
+	}
+
+	lower := strings.ToLower(strings.TrimRight(domain, "."))
+	for start := 0; ; {
+		if _, ok := m.patterns[lower[start:]]; ok {
+			return true, lower[start:], nil
+		}
+		dot := strings.IndexByte(lower[start:], '.')
+		if dot < 0 {
+			break
+		}
+		start += dot + 1
+	}
+	return true, "", nil
+}
+
 // Serialize emits a portable buffer (same-endian) for the database.
 func (m *StaticDomainSet) Serialize() ([]byte, error) {
 	serSize := C.hm_domain_serialized_size(m.db)
@@ -115,11 +425,73 @@ func (m *StaticDomainSet) Serialize() ([]byte, error) {
 	return ser, nil
 }
 
-// FromSerialized reconstructs a StaticDomainSet from a serialized buffer.
+// SerializeEncoded is like Serialize but stores the domain blob in the
+// delta-compressed format puregostaticdomainset.SerializeEncoded implements:
+// each domain is recorded relative to the previous one in blob order as
+// (shared_suffix_len, unique_prefix_bytes), which pays off because Compile
+// already groups popular suffixes together and lays out each bucket's
+// candidates next to each other. libhipermap has no delta-encoding
+// entrypoint of its own, so this hands the plain Serialize output to the
+// pure Go implementation, which understands the wire format bit for bit
+// (see TestPureCGOSerializationByteIdentical), and returns its encoded
+// bytes. The on-disk magic is bumped so a reader that only understands the
+// plain format rejects the buffer with a clear error instead of
+// misparsing it. FromSerialized decodes either format transparently.
+func (m *StaticDomainSet) SerializeEncoded() ([]byte, error) {
+	plain, err := m.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	pureSet, err := puregostaticdomainset.FromSerialized(plain)
+	if err != nil {
+		return nil, err
+	}
+	return pureSet.SerializeEncoded()
+}
+
+// SerializeCompressed is like Serialize, but the domain blob is compressed
+// against a dictionary trained from the blob's own content instead of
+// stored verbatim or delta-encoded, trading extra CPU at serialize time for
+// a smaller buffer — useful when shipping large blocklists over the wire.
+// libhipermap has no dictionary-compression entrypoint of its own, so, like
+// SerializeEncoded, this hands the plain Serialize output to the pure Go
+// implementation and returns its compressed bytes. The on-disk magic is
+// bumped again so a reader that only understands one of the other formats
+// rejects the buffer with a clear error instead of misparsing it.
+// FromSerialized decodes any of the three formats transparently.
+func (m *StaticDomainSet) SerializeCompressed() ([]byte, error) {
+	plain, err := m.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	pureSet, err := puregostaticdomainset.FromSerialized(plain)
+	if err != nil {
+		return nil, err
+	}
+	return pureSet.SerializeCompressed()
+}
+
+// FromSerialized reconstructs a StaticDomainSet from a serialized buffer
+// produced by Serialize, SerializeEncoded, or SerializeCompressed.
+// libhipermap's hm_domain_deserialize only understands the plain format
+// Serialize emits; a buffer carrying one of the other magics is first
+// decoded by the pure Go implementation (which understands all three) and
+// re-serialized in the plain format before being handed to
+// hm_domain_deserialize.
 func FromSerialized(buffer []byte) (*StaticDomainSet, error) {
-	if len(buffer) == 0 {
+	if len(buffer) < 4 {
 		return nil, fmt.Errorf("empty buffer")
 	}
+	if binary.LittleEndian.Uint32(buffer[:4]) != magicUint32 {
+		pureSet, err := puregostaticdomainset.FromSerialized(buffer)
+		if err != nil {
+			return nil, err
+		}
+		buffer, err = pureSet.Serialize()
+		if err != nil {
+			return nil, err
+		}
+	}
 	var dbPlaceSize C.size_t
 	hmErr := C.hm_domain_db_place_size_from_serialized(
 		&dbPlaceSize,
@@ -172,6 +544,13 @@ func (m *StaticDomainSet) String() string {
 		usedTotal, popCount, fillPct, usedBytes, header, popular, table, blob)
 }
 
+// PopularContainerStats reports the popular-hash presence bitmap's
+// per-container-type breakdown; see the type's doc comment for why this is
+// always the zero value.
+func (m *StaticDomainSet) PopularContainerStats() PopularContainerStats {
+	return PopularContainerStats{}
+}
+
 // Seed returns the internal hash seed used by the database calibration.
 func (m *StaticDomainSet) Seed() uint32 {
 	if m == nil || m.db == nil {
@@ -187,3 +566,28 @@ func (m *StaticDomainSet) Allocated() int {
 	}
 	return len(m.dbPlace)
 }
+
+// FingerprintBits returns the CompileOptions.FingerprintBits this database
+// was compiled with.
+func (m *StaticDomainSet) FingerprintBits() FingerprintBits {
+	if m == nil || m.db == nil {
+		return FingerprintNone
+	}
+	return m.fingerprintBits
+}
+
+// Close releases resources held by m. It is a no-op kept safe to call on
+// every *StaticDomainSet this package returns, including from
+// FromSerializedMmap, for symmetry with the mmap-backed loaders in
+// gostaticuint64set and gosm, which likewise copy and unmap their file
+// before returning. After Close, m must not be used.
+func (m *StaticDomainSet) Close() error {
+	if m == nil || m.mmap == nil {
+		return nil
+	}
+	region := m.mmap
+	m.mmap = nil
+	m.dbPlace = nil
+	m.db = nil
+	return syscall.Munmap(region)
+}
@@ -7,17 +7,133 @@ import (
 	"errors"
 	"strings"
 
+	"github.com/starius/hipermap/idna"
 	puregostaticdomainset "github.com/starius/hipermap/puregostaticdomainset"
 )
 
 type StaticDomainSet = puregostaticdomainset.StaticDomainSet
 
+// FingerprintBits selects, in the cgo implementation, whether Find re-checks
+// each hit against the compiled domain set; every non-zero width does the
+// same exact check there, and no fingerprint is stored. The pure Go
+// implementation already verifies the full candidate bytes against the blob
+// on every popular-suffix check, so it has no false-positive to guard
+// against; CompileWithOptions accepts the option for API parity but ignores
+// it.
+//
+// A 2-choice/cuckoo table was considered instead of this byte-verification
+// approach, but rejected: relaying out StaticDomainSet's buckets would
+// change the on-disk format and break byte-parity with the cgo database
+// (pure_compat_test.go), for no gain here since popularSuffixExists already
+// makes growth decisions independent of any hash collision. See
+// TestPlacementInvariant_OrderIndependent in the parent package for the
+// resulting invariant.
+type FingerprintBits int
+
+const (
+	FingerprintNone FingerprintBits = 0
+	Fingerprint16   FingerprintBits = 16
+	Fingerprint32   FingerprintBits = 32
+	Fingerprint48   FingerprintBits = 48
+)
+
+// PopularEncoding names a container layout for the popular-hash presence
+// bitmap, but it has no effect in either build: the cgo implementation
+// stores that bitmap in one fixed layout, and the pure Go implementation
+// keeps popular suffixes as a plain sorted record slice, so this type and
+// CompileOptions.PopularEncoding exist only for API parity.
+type PopularEncoding int
+
+const (
+	PopularEncodingAuto   PopularEncoding = 0
+	PopularEncodingArray  PopularEncoding = 1
+	PopularEncodingBitmap PopularEncoding = 2
+)
+
+// PopularContainerStats mirrors the cgo implementation's per-container-type
+// breakdown of the popular-hash presence bitmap. The pure Go implementation
+// has no such bitmap, so PopularContainerStats is always the zero value.
+type PopularContainerStats = puregostaticdomainset.PopularContainerStats
+
+// Result and ResultSet alias the pure Go implementation's FindBatch/
+// SubmitBatch result types, for API parity with the cgo build's batch.go
+// under the use_pure_gostaticdomainset build tag.
+type Result = puregostaticdomainset.Result
+type ResultSet = puregostaticdomainset.ResultSet
+
+// IDNMode selects how Compile and Find treat non-ASCII domain labels. See
+// the idna package for the underlying ToASCII implementation.
+type IDNMode = idna.Mode
+
+const (
+	IDNOff          = idna.Off
+	IDNTransitional = idna.Transitional
+	IDNStrict       = idna.Strict
+)
+
+// CompileOptions controls optional behavior of Compile.
+type CompileOptions struct {
+	// FingerprintBits has no effect in the pure Go implementation; see
+	// FingerprintBits for why.
+	FingerprintBits FingerprintBits
+
+	// SuffixMatch, when true, rejects with ErrTopLevelDomain a pattern that
+	// is itself a public suffix by the Public Suffix List (see SetPSL) and
+	// records the patterns so FindDetails can report which one matched.
+	// Find's matching is the same with or without it.
+	SuffixMatch bool
+
+	// PopularEncoding has no effect in the pure Go implementation; see
+	// PopularEncoding for why.
+	PopularEncoding PopularEncoding
+
+	// IDN selects how non-ASCII domain labels are handled. Zero value is
+	// IDNOff, which rejects non-ASCII input exactly as Compile always has.
+	IDN IDNMode
+}
+
 // Compile builds a static domain set using the pure Go implementation.
 func Compile(domains []string) (*StaticDomainSet, error) {
+	return CompileWithOptions(domains, CompileOptions{})
+}
+
+// CompileWithOptions builds a static domain set using the pure Go
+// implementation. opts.FingerprintBits is accepted for API parity with the
+// cgo implementation but is otherwise unused; see FingerprintBits.
+func CompileWithOptions(domains []string, opts CompileOptions) (*StaticDomainSet, error) {
+	if opts.IDN != IDNOff {
+		converted := make([]string, len(domains))
+		for i, s := range domains {
+			ascii, err := idna.ToASCII(s, opts.IDN)
+			if err != nil {
+				return nil, ErrInvalidIDN
+			}
+			converted[i] = ascii
+		}
+		domains = converted
+	}
+
+	if opts.SuffixMatch {
+		for _, s := range domains {
+			if s == "" {
+				return nil, ErrEmptyDomain
+			}
+			lower := strings.ToLower(strings.TrimRight(s, "."))
+			if registrableDomainSpan(lower) < 0 {
+				return nil, ErrTopLevelDomain
+			}
+		}
+	}
 	ds, err := puregostaticdomainset.Compile(domains)
 	if err != nil {
 		return nil, mapPureError(err)
 	}
+	if opts.SuffixMatch {
+		ds.SetPatternsForSuffixMatch(domains)
+	}
+	if opts.IDN != IDNOff {
+		ds.SetIDNMode(opts.IDN)
+	}
 	return ds, nil
 }
 
@@ -44,6 +160,8 @@ func mapPureError(err error) error {
 		return ErrTooManyPopularDomains
 	case errors.Is(err, puregostaticdomainset.ErrFailedToCalibrate):
 		return ErrFailedToCalibrate
+	case errors.Is(err, puregostaticdomainset.ErrInvalidIDN):
+		return ErrInvalidIDN
 	case err != nil && strings.HasPrefix(err.Error(), "invalid length"):
 		return ErrBadValue
 	default:
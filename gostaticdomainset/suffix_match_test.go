@@ -0,0 +1,49 @@
+package gostaticdomainset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuffixMatch_RejectsBarePublicSuffix(t *testing.T) {
+	_, err := CompileWithOptions([]string{"co.uk"}, CompileOptions{SuffixMatch: true})
+	require.ErrorIs(t, err, ErrTopLevelDomain)
+
+	_, err = CompileWithOptions([]string{"com"}, CompileOptions{SuffixMatch: true})
+	require.ErrorIs(t, err, ErrTopLevelDomain)
+}
+
+func TestSuffixMatch_FindDetails(t *testing.T) {
+	ds, err := CompileWithOptions([]string{"example.co.uk"}, CompileOptions{SuffixMatch: true})
+	require.NoError(t, err)
+
+	matched, pattern, err := ds.FindDetails("foo.example.co.uk")
+	require.NoError(t, err)
+	require.True(t, matched)
+	require.Equal(t, "example.co.uk", pattern)
+
+	matched, _, err = ds.FindDetails("evil-example.co.uk")
+	require.NoError(t, err)
+	require.False(t, matched)
+
+	matched, _, err = ds.FindDetails("co.uk")
+	require.NoError(t, err)
+	require.False(t, matched)
+}
+
+func TestSuffixMatch_CustomPSL(t *testing.T) {
+	err := SetPSL(strings.NewReader("example.custom\n"))
+	require.NoError(t, err)
+	defer SetPSL(strings.NewReader(defaultPSLData))
+
+	_, err = CompileWithOptions([]string{"example.custom"}, CompileOptions{SuffixMatch: true})
+	require.ErrorIs(t, err, ErrTopLevelDomain)
+
+	ds, err := CompileWithOptions([]string{"foo.example.custom"}, CompileOptions{SuffixMatch: true})
+	require.NoError(t, err)
+	ok, err := ds.Find("bar.foo.example.custom")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
@@ -0,0 +1,45 @@
+package gostaticuint64map
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindBatch(t *testing.T) {
+	db, err := Compile(map[uint64]uint64{1: 2, 2: 3, 5: 9})
+	require.NoError(t, err)
+
+	keys := []uint64{1, 2, 3, 5, 0}
+	out := make([]uint64, len(keys))
+	require.NoError(t, db.FindBatch(keys, out))
+
+	for i, k := range keys {
+		require.Equal(t, db.Find(k), out[i])
+	}
+}
+
+func TestFindBatchLengthMismatch(t *testing.T) {
+	db, err := Compile(map[uint64]uint64{1: 2})
+	require.NoError(t, err)
+	require.Error(t, db.FindBatch([]uint64{1, 2}, []uint64{0}))
+}
+
+func TestSubmitBatchReapAndWait(t *testing.T) {
+	db, err := Compile(map[uint64]uint64{1: 2, 2: 3, 5: 9})
+	require.NoError(t, err)
+
+	keys := []uint64{1, 2, 3, 5, 0}
+	rs := db.SubmitBatch(keys)
+	results := rs.Wait()
+	require.Len(t, results, len(keys))
+
+	for i, k := range keys {
+		require.NotNil(t, results[i])
+		require.Equal(t, db.Find(k), results[i].Value)
+
+		latest, ok := rs.LatestResult(i)
+		require.True(t, ok)
+		require.Equal(t, db.Find(k), latest.Value)
+	}
+}
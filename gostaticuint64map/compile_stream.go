@@ -0,0 +1,68 @@
+package gostaticuint64map
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// progressInterval is how many scanned lines pass between ProgressFunc
+// calls, so the callback overhead stays negligible even on huge feeds.
+const progressInterval = 100000
+
+// ProgressFunc is called periodically during CompileStreamKV with the
+// number of lines scanned so far.
+type ProgressFunc func(linesScanned int)
+
+// CompileStreamKV builds a StaticUint64Map from r instead of requiring
+// fully materialized keys/values slices. Each line must be "key=value",
+// each number parsed by strconv.ParseUint with base 0: decimal, or with a
+// "0x" (hex), "0o" or leading "0" (octal) or "0b" (binary) prefix; blank
+// lines are skipped. Lines are read one
+// at a time (bufio.Scanner) so the whole input is never buffered at once,
+// and progress is reported via progress (which may be nil).
+func CompileStreamKV(r io.Reader, progress ProgressFunc) (*StaticUint64Map, error) {
+	var keys, values []uint64
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lines := 0
+	for scanner.Scan() {
+		lines++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("CompileStreamKV: line %d: missing '=': %q", lines, line)
+		}
+		key, err := strconv.ParseUint(strings.TrimSpace(k), 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("CompileStreamKV: line %d: bad key: %w", lines, err)
+		}
+		value, err := strconv.ParseUint(strings.TrimSpace(v), 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("CompileStreamKV: line %d: bad value: %w", lines, err)
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+
+		if progress != nil && lines%progressInterval == 0 {
+			progress(lines)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if progress != nil {
+		progress(lines)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys")
+	}
+	return CompileKeyValues(keys, values)
+}
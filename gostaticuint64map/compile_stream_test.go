@@ -0,0 +1,34 @@
+package gostaticuint64map
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileStreamKV(t *testing.T) {
+	input := "1=2\n2=3\n\n0xa=0x9\n"
+
+	var progressCalls []int
+	db, err := CompileStreamKV(strings.NewReader(input), func(n int) {
+		progressCalls = append(progressCalls, n)
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, progressCalls)
+
+	require.Equal(t, uint64(2), db.Find(1))
+	require.Equal(t, uint64(3), db.Find(2))
+	require.Equal(t, uint64(9), db.Find(0xa))
+	require.Equal(t, uint64(0), db.Find(100))
+}
+
+func TestCompileStreamKVBadLine(t *testing.T) {
+	_, err := CompileStreamKV(strings.NewReader("not-a-kv-line\n"), nil)
+	require.Error(t, err)
+}
+
+func TestCompileStreamKVEmpty(t *testing.T) {
+	_, err := CompileStreamKV(strings.NewReader(""), nil)
+	require.Error(t, err)
+}
@@ -1,7 +1,10 @@
 package gostaticuint64set
 
 import (
+	"encoding/binary"
 	"fmt"
+	"sort"
+	"syscall"
 	"unsafe"
 )
 
@@ -11,7 +14,26 @@ import "C"
 
 type StaticUint64Set struct {
 	dbPlace []byte
+	mmap    []byte
 	db      *C.hm_u64_database_t
+
+	// keys is a sorted copy of the compiled key set, kept around only so
+	// SerializePacked has something to delta-encode; it is nil on a set
+	// loaded via the raw FromSerialized path, which never sees the keys.
+	keys []uint64
+}
+
+// Close releases resources held by m. It unmaps m.mmap if that is set; no
+// loader in this package sets it (FromMappedFile unmaps its file before
+// returning), so Close is currently a no-op, though calling it is always
+// safe.
+func (m *StaticUint64Set) Close() error {
+	if m.mmap == nil {
+		return nil
+	}
+	region := m.mmap
+	m.mmap = nil
+	return syscall.Munmap(region)
 }
 
 func Compile(keys []uint64) (*StaticUint64Set, error) {
@@ -32,9 +54,14 @@ func Compile(keys []uint64) (*StaticUint64Set, error) {
 	if hmErr != C.HM_SUCCESS {
 		return nil, fmt.Errorf("hm_u64_compile failed: %d", hmErr)
 	}
+
+	sortedKeys := append([]uint64(nil), keys...)
+	sort.Slice(sortedKeys, func(i, j int) bool { return sortedKeys[i] < sortedKeys[j] })
+
 	return &StaticUint64Set{
 		dbPlace: dbPlace,
 		db:      db,
+		keys:    sortedKeys,
 	}, nil
 }
 
@@ -60,11 +87,20 @@ func (m *StaticUint64Set) Serialize() ([]byte, error) {
 	return ser, nil
 }
 
+// FromSerialized reconstructs a StaticUint64Set from a buffer produced by
+// either Serialize or SerializePacked; the two formats are distinguished by
+// a leading magic (see packedMagic in delta.go) so both can coexist.
 func FromSerialized(buffer []byte) (*StaticUint64Set, error) {
 	if len(buffer) == 0 {
 		return nil, fmt.Errorf("empty buffer")
 	}
+	if len(buffer) >= 4 && binary.LittleEndian.Uint32(buffer[:4]) == packedMagic {
+		return FromSerializedPacked(buffer)
+	}
+	return fromSerializedRaw(buffer)
+}
 
+func fromSerializedRaw(buffer []byte) (*StaticUint64Set, error) {
 	var dbPlaceSize C.size_t
 	hmErr := C.hm_u64_db_place_size_from_serialized(
 		&dbPlaceSize,
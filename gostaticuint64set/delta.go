@@ -0,0 +1,308 @@
+package gostaticuint64set
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// packedMagic identifies the double-delta packed format SerializePacked
+// writes: "K64D" in little-endian bytes. It is unrelated to the opaque
+// on-wire layout hm_u64_serialize produces (no source for that format is
+// available in this tree, so its own framing is not something this package
+// can inspect); FromSerialized only needs the two magics to never collide.
+const packedMagic = 0x4436344B
+
+const packedVersion = 1
+
+// deltaBlockSize is how many second-difference values share one bit width,
+// the same block size Prometheus's chunk encoding groups values in.
+const deltaBlockSize = 128
+
+// packedHeaderBytes is magic(4) + version(1) + count(4) + base(8) + d1[0](8).
+const packedHeaderBytes = 4 + 1 + 4 + 8 + 8
+
+// SerializePacked encodes the compiled key set as a sorted double-delta
+// stream: first differences d1[i] = k[i]-k[i-1], then second differences
+// d2[i] = d1[i]-d1[i-1], bit-packed in blocks of deltaBlockSize values with
+// a per-block bit width and an escape path for values that overflow it.
+// This typically cuts the size of a sorted, slowly-varying key set well
+// below the raw hm_u64_serialize layout, at the cost of re-running Compile
+// on load (FromSerializedPacked rebuilds the perfect-hash structure from
+// the decoded keys rather than restoring the C layout directly).
+//
+// SerializePacked is only available on a set built by Compile or
+// FromSerializedPacked, which retain the sorted key list; a set loaded via
+// the raw FromSerialized path has no keys to re-encode.
+func (m *StaticUint64Set) SerializePacked() ([]byte, error) {
+	if m.keys == nil {
+		return nil, fmt.Errorf("SerializePacked: key list not available (set was loaded from the raw format)")
+	}
+	return encodeDoubleDelta(m.keys), nil
+}
+
+// FromSerializedPacked decodes a buffer produced by SerializePacked back
+// into the exact key list, then calls Compile to rebuild the perfect-hash
+// structure, so the on-disk packed form is fully decoupled from the C
+// layout Compile happens to produce.
+func FromSerializedPacked(data []byte) (*StaticUint64Set, error) {
+	keys, err := decodeDoubleDelta(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("FromSerializedPacked: empty key set")
+	}
+	m, err := Compile(keys)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func zigzagEncode(v int64) uint64 { return uint64((v << 1) ^ (v >> 63)) }
+func zigzagDecode(v uint64) int64 { return int64(v>>1) ^ -int64(v&1) }
+
+func bitsLen64(v uint64) uint {
+	n := uint(0)
+	for v > 0 {
+		v >>= 1
+		n++
+	}
+	if n == 0 {
+		n = 1
+	}
+	return n
+}
+
+// bitWidthFor picks a bit width covering the 90th percentile of values in
+// block, so a handful of outliers go through the escape path instead of
+// forcing every value in the block to pay for the widest one.
+func bitWidthFor(block []uint64) uint {
+	widths := make([]uint, len(block))
+	for i, v := range block {
+		widths[i] = bitsLen64(v)
+	}
+	sort.Slice(widths, func(i, j int) bool { return widths[i] < widths[j] })
+	idx := (len(widths) * 9) / 10
+	if idx >= len(widths) {
+		idx = len(widths) - 1
+	}
+	w := widths[idx]
+	if w > 63 {
+		w = 63
+	}
+	return w
+}
+
+func sentinelFor(w uint) uint64 {
+	return (uint64(1) << w) - 1
+}
+
+type bitWriter struct {
+	buf   []byte
+	bits  uint64
+	nbits uint
+}
+
+// writeBits appends the low n bits of v (n <= 32, so bits never overflows a
+// uint64 once combined with the <8 leftover bits from the previous call).
+func (w *bitWriter) writeBits(v uint64, n uint) {
+	w.bits |= (v & ((uint64(1) << n) - 1)) << w.nbits
+	w.nbits += n
+	for w.nbits >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.nbits -= 8
+	}
+}
+
+// writeBitsWide is writeBits for n up to 64, splitting into <=32-bit chunks.
+func (w *bitWriter) writeBitsWide(v uint64, n uint) {
+	for n > 0 {
+		take := n
+		if take > 32 {
+			take = 32
+		}
+		w.writeBits(v, take)
+		v >>= take
+		n -= take
+	}
+}
+
+func (w *bitWriter) flush() []byte {
+	if w.nbits > 0 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits, w.nbits = 0, 0
+	}
+	return w.buf
+}
+
+type bitReader struct {
+	buf   []byte
+	pos   int
+	bits  uint64
+	nbits uint
+}
+
+// readBits reads n <= 32 bits, for the same overflow-avoidance reason as
+// bitWriter.writeBits.
+func (r *bitReader) readBits(n uint) (uint64, error) {
+	for r.nbits < n {
+		if r.pos >= len(r.buf) {
+			return 0, fmt.Errorf("unexpected end of packed delta stream")
+		}
+		r.bits |= uint64(r.buf[r.pos]) << r.nbits
+		r.pos++
+		r.nbits += 8
+	}
+	v := r.bits & ((uint64(1) << n) - 1)
+	r.bits >>= n
+	r.nbits -= n
+	return v, nil
+}
+
+func (r *bitReader) readBitsWide(n uint) (uint64, error) {
+	var result uint64
+	var shift uint
+	for n > 0 {
+		take := n
+		if take > 32 {
+			take = 32
+		}
+		v, err := r.readBits(take)
+		if err != nil {
+			return 0, err
+		}
+		result |= v << shift
+		shift += take
+		n -= take
+	}
+	return result, nil
+}
+
+func writeDeltaBlock(w *bitWriter, block []uint64) {
+	bw := bitWidthFor(block)
+	w.writeBits(uint64(bw), 8)
+	w.writeBits(uint64(len(block)), 8)
+	sentinel := sentinelFor(bw)
+	for _, v := range block {
+		if v < sentinel {
+			w.writeBitsWide(v, bw)
+			continue
+		}
+		w.writeBitsWide(sentinel, bw)
+		w.writeBitsWide(v, 64)
+	}
+}
+
+// encodeDoubleDelta implements the wire format documented on SerializePacked.
+func encodeDoubleDelta(keys []uint64) []byte {
+	n := len(keys)
+	header := make([]byte, 0, packedHeaderBytes)
+	header = binary.LittleEndian.AppendUint32(header, packedMagic)
+	header = append(header, packedVersion)
+	header = binary.LittleEndian.AppendUint32(header, uint32(n))
+
+	var base, d1zz uint64
+	if n > 0 {
+		base = keys[0]
+	}
+	if n > 1 {
+		d1zz = zigzagEncode(int64(keys[1]) - int64(keys[0]))
+	}
+	header = binary.LittleEndian.AppendUint64(header, base)
+	header = binary.LittleEndian.AppendUint64(header, d1zz)
+
+	var bw bitWriter
+	if n > 2 {
+		prev1 := int64(keys[1]) - int64(keys[0])
+		block := make([]uint64, 0, deltaBlockSize)
+		for i := 2; i < n; i++ {
+			d1 := int64(keys[i]) - int64(keys[i-1])
+			d2 := d1 - prev1
+			block = append(block, zigzagEncode(d2))
+			prev1 = d1
+			if len(block) == deltaBlockSize || i == n-1 {
+				writeDeltaBlock(&bw, block)
+				block = block[:0]
+			}
+		}
+	}
+
+	return append(header, bw.flush()...)
+}
+
+// decodeDoubleDelta is the inverse of encodeDoubleDelta.
+func decodeDoubleDelta(data []byte) ([]uint64, error) {
+	if len(data) < packedHeaderBytes {
+		return nil, fmt.Errorf("packed data too short")
+	}
+	magic := binary.LittleEndian.Uint32(data[0:4])
+	if magic != packedMagic {
+		return nil, fmt.Errorf("bad packed magic: %#x", magic)
+	}
+	version := data[4]
+	if version != packedVersion {
+		return nil, fmt.Errorf("unsupported packed version: %d", version)
+	}
+	n := int(binary.LittleEndian.Uint32(data[5:9]))
+	base := binary.LittleEndian.Uint64(data[9:17])
+	d1zz := binary.LittleEndian.Uint64(data[17:25])
+
+	if n == 0 {
+		return nil, nil
+	}
+	if n > 2 {
+		// A block costs at least 2 header bytes plus >=1 bit per packed
+		// value (the true minimum is higher for any non-degenerate bit
+		// width, but 1 bit/value is a safe lower bound), so a claimed n
+		// whose minimum encoding wouldn't fit in the remaining buffer is
+		// corrupt; reject it before allocating keys sized by n.
+		numBlocks := (n - 2 + deltaBlockSize - 1) / deltaBlockSize
+		minBytes := numBlocks*2 + (n-2+7)/8
+		if minBytes > len(data)-packedHeaderBytes {
+			return nil, fmt.Errorf("packed data too short for claimed count %d", n)
+		}
+	}
+	keys := make([]uint64, 0, n)
+	keys = append(keys, base)
+	if n == 1 {
+		return keys, nil
+	}
+
+	prev1 := zigzagDecode(d1zz)
+	keys = append(keys, uint64(int64(base)+prev1))
+
+	r := &bitReader{buf: data[packedHeaderBytes:]}
+	for len(keys) < n {
+		bw, err := r.readBits(8)
+		if err != nil {
+			return nil, err
+		}
+		count, err := r.readBits(8)
+		if err != nil {
+			return nil, err
+		}
+		sentinel := sentinelFor(uint(bw))
+		for i := 0; i < int(count); i++ {
+			v, err := r.readBitsWide(uint(bw))
+			if err != nil {
+				return nil, err
+			}
+			if v == sentinel {
+				v, err = r.readBitsWide(64)
+				if err != nil {
+					return nil, err
+				}
+			}
+			d2 := zigzagDecode(v)
+			d1 := prev1 + d2
+			prevKey := keys[len(keys)-1]
+			keys = append(keys, uint64(int64(prevKey)+d1))
+			prev1 = d1
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,133 @@
+package gostaticuint64set
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func denseAscendingKeys(n int) []uint64 {
+	keys := make([]uint64, n)
+	for i := range keys {
+		keys[i] = uint64(i) * 3
+	}
+	return keys
+}
+
+func sparseRandomKeys(r *rand.Rand, n int) []uint64 {
+	set := make(map[uint64]struct{}, n)
+	for len(set) < n {
+		set[r.Uint64()] = struct{}{}
+	}
+	keys := make([]uint64, 0, n)
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+func clusteredKeys(r *rand.Rand, clusters, perCluster int) []uint64 {
+	set := make(map[uint64]struct{}, clusters*perCluster)
+	for c := 0; c < clusters; c++ {
+		base := r.Uint64() >> 8
+		for i := 0; i < perCluster; i++ {
+			set[base+uint64(r.Intn(64))] = struct{}{}
+		}
+	}
+	keys := make([]uint64, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+func TestDeltaRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(9))
+
+	cases := map[string][]uint64{
+		"dense_ascending": denseAscendingKeys(5000),
+		"sparse_random":   sparseRandomKeys(r, 5000),
+		"clustered":       clusteredKeys(r, 50, 100),
+		"single_key":      {42},
+		"two_keys":        {10, 20},
+	}
+
+	for name, keys := range cases {
+		t.Run(name, func(t *testing.T) {
+			packed := encodeDoubleDelta(keys)
+			got, err := decodeDoubleDelta(packed)
+			require.NoError(t, err)
+			require.Equal(t, keys, got)
+		})
+	}
+}
+
+func TestDeltaDecodeRejectsBadMagic(t *testing.T) {
+	_, err := decodeDoubleDelta(make([]byte, packedHeaderBytes))
+	require.Error(t, err)
+}
+
+func TestDeltaDecodeRejectsTruncated(t *testing.T) {
+	packed := encodeDoubleDelta(denseAscendingKeys(1000))
+	_, err := decodeDoubleDelta(packed[:len(packed)-5])
+	require.Error(t, err)
+}
+
+func TestDeltaDecodeRejectsHugeClaimedCount(t *testing.T) {
+	packed := encodeDoubleDelta(denseAscendingKeys(3))
+	binary.LittleEndian.PutUint32(packed[5:9], 0xFFFFFFF0)
+	_, err := decodeDoubleDelta(packed)
+	require.Error(t, err)
+}
+
+func TestSerializePackedSmallerThanRawForTypicalInput(t *testing.T) {
+	keys := denseAscendingKeys(20000)
+
+	db, err := Compile(keys)
+	require.NoError(t, err)
+
+	raw, err := db.Serialize()
+	require.NoError(t, err)
+
+	packed, err := db.SerializePacked()
+	require.NoError(t, err)
+
+	require.Lessf(t, len(packed), len(raw), "packed=%d raw=%d", len(packed), len(raw))
+
+	restored, err := FromSerializedPacked(packed)
+	require.NoError(t, err)
+	for _, k := range keys {
+		require.True(t, restored.Find(k))
+	}
+}
+
+func TestSerializePackedUnavailableAfterRawLoad(t *testing.T) {
+	db, err := Compile(denseAscendingKeys(10))
+	require.NoError(t, err)
+
+	raw, err := db.Serialize()
+	require.NoError(t, err)
+
+	loaded, err := FromSerialized(raw)
+	require.NoError(t, err)
+
+	_, err = loaded.SerializePacked()
+	require.Error(t, err)
+}
+
+func TestFromSerializedDispatchesOnMagic(t *testing.T) {
+	db, err := Compile(denseAscendingKeys(100))
+	require.NoError(t, err)
+
+	packed, err := db.SerializePacked()
+	require.NoError(t, err)
+
+	loaded, err := FromSerialized(packed)
+	require.NoError(t, err)
+	require.True(t, loaded.Find(0))
+}
@@ -0,0 +1,21 @@
+package gostaticuint64set
+
+import (
+	"fmt"
+)
+
+// FindMany evaluates keys against the set, writing one bool per key into
+// out. libhipermap has no hm_u64_find_many entrypoint to amortize the
+// Go-to-C call overhead of repeated Find calls, so this just loops over the
+// real hm_u64_find-backed Find. out must have the same length as keys;
+// FindMany writes into it positionally.
+func (m *StaticUint64Set) FindMany(keys []uint64, out []bool) error {
+	if len(keys) != len(out) {
+		return fmt.Errorf("FindMany: len(out)=%d != len(keys)=%d", len(out), len(keys))
+	}
+
+	for i, k := range keys {
+		out[i] = m.Find(k)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package gostaticuint64set
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindMany(t *testing.T) {
+	r := rand.New(rand.NewSource(400))
+
+	const N = 1000
+	keys := make([]uint64, 0, N)
+	set := make(map[uint64]struct{}, N)
+	for len(keys) < N {
+		key := r.Uint64()
+		if key == 0 {
+			continue
+		}
+		if _, has := set[key]; has {
+			continue
+		}
+		set[key] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	db, err := Compile(keys)
+	require.NoError(t, err)
+
+	queries := make([]uint64, 0, len(keys)*2)
+	for _, key := range keys {
+		queries = append(queries, key, key+1)
+	}
+
+	out := make([]bool, len(queries))
+	require.NoError(t, db.FindMany(queries, out))
+
+	for i, q := range queries {
+		require.Equal(t, db.Find(q), out[i], q)
+	}
+}
+
+func TestFindManyLengthMismatch(t *testing.T) {
+	db, err := Compile([]uint64{1, 2, 3})
+	require.NoError(t, err)
+	require.Error(t, db.FindMany([]uint64{1, 2}, []bool{false}))
+}
+
+func BenchmarkFindLarge(b *testing.B) {
+	r := rand.New(rand.NewSource(300))
+
+	const N = 1500
+	keys := make([]uint64, 0, N)
+	set := make(map[uint64]struct{}, N)
+	for len(keys) < N {
+		key := r.Uint64()
+		if key == 0 {
+			continue
+		}
+		if _, has := set[key]; has {
+			continue
+		}
+		set[key] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	db, err := Compile(keys)
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	j := 0
+	for i := 0; i < b.N; i++ {
+		if j == len(keys) {
+			j = 0
+		}
+		_ = db.Find(keys[j])
+		j++
+	}
+}
+
+func BenchmarkFindManyLarge(b *testing.B) {
+	r := rand.New(rand.NewSource(300))
+
+	const N = 1500
+	keys := make([]uint64, 0, N)
+	set := make(map[uint64]struct{}, N)
+	for len(keys) < N {
+		key := r.Uint64()
+		if key == 0 {
+			continue
+		}
+		if _, has := set[key]; has {
+			continue
+		}
+		set[key] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	db, err := Compile(keys)
+	require.NoError(b, err)
+
+	out := make([]bool, len(keys))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := db.FindMany(keys, out); err != nil {
+			b.Fatalf("FindMany: %v", err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package gostaticuint64set
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// magicUint32 is the 4-byte magic every serialized StaticUint64Set begins
+// with ("U64H" in little-endian bytes), checked against the mapped bytes
+// directly so a corrupt or foreign file is rejected before being handed to
+// FromSerialized.
+const magicUint32 = 0x48343655
+
+// FromMappedFile loads a StaticUint64Set from path by mmap'ing it instead
+// of reading it into a heap buffer first, then reconstructing the database
+// the same way FromSerialized does. The real libhipermap has no in-place
+// deserializer (only hm_u64_deserialize, which always copies into a fresh
+// db_place), so this does not avoid that copy — it only avoids the
+// separate read(2) and buffer allocation FromSerialized would otherwise
+// need to get the bytes off disk. The mapping is released once the copy
+// into db_place is complete.
+//
+// path must contain the exact bytes produced by Serialize. Close on the
+// returned *StaticUint64Set is a no-op kept safe to call for symmetry with
+// the other FromMappedFile loaders in this tree, which likewise copy and
+// unmap their file before returning.
+func FromMappedFile(path string) (*StaticUint64Set, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := fi.Size()
+	if size < 4 {
+		return nil, fmt.Errorf("empty file")
+	}
+
+	region, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("mmap %s: %w", path, err)
+	}
+	defer syscall.Munmap(region)
+
+	if binary.LittleEndian.Uint32(region[:4]) != magicUint32 {
+		return nil, fmt.Errorf("bad magic")
+	}
+
+	return FromSerialized(region)
+}
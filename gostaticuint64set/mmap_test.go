@@ -0,0 +1,40 @@
+package gostaticuint64set
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromMappedFile_RoundTrip(t *testing.T) {
+	keys := []uint64{1, 2, 3, 100, 1 << 40}
+	db, err := Compile(keys)
+	require.NoError(t, err)
+
+	ser, err := db.Serialize()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "set.db")
+	require.NoError(t, os.WriteFile(path, ser, 0o644))
+
+	loaded, err := FromMappedFile(path)
+	require.NoError(t, err)
+	defer loaded.Close()
+
+	for _, k := range keys {
+		require.True(t, loaded.Find(k))
+	}
+	require.False(t, loaded.Find(42))
+}
+
+func TestFromMappedFile_RejectsBadMagic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "garbage.db")
+	require.NoError(t, os.WriteFile(path, make([]byte, 16), 0o644))
+
+	_, err := FromMappedFile(path)
+	require.Error(t, err)
+}
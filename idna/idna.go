@@ -0,0 +1,90 @@
+// Package idna implements a minimal, dependency-free ASCII-compatible
+// encoding for internationalized domain labels: Unicode lowercasing plus
+// RFC 3492 Punycode, good enough to let gostaticdomainset accept Unicode
+// domain input without pulling in golang.org/x/net/idna. It does not
+// implement the full IDNA2008/UTS-46 mapping and validation tables (context
+// rules, disallowed/deviation code point lists); ToASCII is meant for
+// well-formed domain input, not for validating attacker-controlled strings
+// against the full IDNA profile.
+package idna
+
+import (
+	"errors"
+	"strings"
+)
+
+// Mode selects how strictly ToASCII validates a label after mapping.
+type Mode int
+
+const (
+	// Off only lowercases ASCII labels and encodes nothing. ToASCII errors
+	// if domain contains non-ASCII bytes, so callers gate non-ASCII input
+	// on a non-Off mode.
+	Off Mode = iota
+
+	// Transitional accepts any non-empty label after lowercasing, the
+	// permissive behavior most legacy IDNA2003-era resolvers used.
+	Transitional
+
+	// Strict additionally rejects labels that are empty after mapping.
+	Strict
+)
+
+// ErrInvalidLabel is returned by ToASCII when a label fails validation under
+// Strict mode, or when Off mode is given non-ASCII input.
+var ErrInvalidLabel = errors.New("idna: invalid label")
+
+// ToASCII converts every label of domain to its ASCII-compatible encoding:
+// ASCII labels are lowercased in place, non-ASCII labels are lowercased and
+// then Punycode-encoded with the "xn--" ACE prefix. The result compares
+// equal, byte for byte, regardless of whether the input was already ASCII,
+// already punycode, or Unicode, so it can be used to normalize both Compile
+// input and Find queries onto one ASCII pipeline.
+func ToASCII(domain string, mode Mode) (string, error) {
+	if domain == "" {
+		return "", nil
+	}
+	labels := strings.Split(domain, ".")
+	for i, label := range labels {
+		enc, err := encodeLabel(label, mode)
+		if err != nil {
+			return "", err
+		}
+		labels[i] = enc
+	}
+	return strings.Join(labels, "."), nil
+}
+
+func encodeLabel(label string, mode Mode) (string, error) {
+	// Checked ahead of the isASCII short-circuit below: an empty label
+	// (from a leading, trailing or doubled dot in the original domain) is
+	// trivially ASCII and would otherwise pass through untouched under
+	// every mode, never reaching the Strict check further down.
+	if mode == Strict && label == "" {
+		return "", ErrInvalidLabel
+	}
+	if isASCII(label) {
+		return strings.ToLower(label), nil
+	}
+	if mode == Off {
+		return "", ErrInvalidLabel
+	}
+
+	// Stand-in for the UTS-46 mapping step: Unicode-aware lowercasing.
+	lower := strings.ToLower(label)
+
+	encoded, err := punyEncode(lower)
+	if err != nil {
+		return "", err
+	}
+	return "xn--" + encoded, nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package idna
+
+import "testing"
+
+func TestToASCII(t *testing.T) {
+	cases := []struct {
+		name  string
+		in    string
+		mode  Mode
+		want  string
+		isErr bool
+	}{
+		{name: "already ascii", in: "Example.COM", mode: Transitional, want: "example.com"},
+		{name: "already punycode passes through", in: "xn--puny-test.com", mode: Transitional, want: "xn--puny-test.com"},
+		{name: "unicode label encoded", in: "mañana.com", mode: Transitional, want: "xn--maana-pta.com"},
+		{name: "off mode rejects unicode", in: "mañana.com", mode: Off, isErr: true},
+		{name: "off mode passes ascii", in: "example.com", mode: Off, want: "example.com"},
+		{name: "strict accepts well-formed unicode", in: "mañana.com", mode: Strict, want: "xn--maana-pta.com"},
+		{name: "strict rejects empty label", in: "a..com", mode: Strict, isErr: true},
+		{name: "transitional accepts empty label", in: "a..com", mode: Transitional, want: "a..com"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ToASCII(tc.in, tc.mode)
+			if tc.isErr {
+				if err == nil {
+					t.Fatalf("ToASCII(%q): want error, got %q", tc.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToASCII(%q): unexpected error %v", tc.in, err)
+			}
+			if got != tc.want {
+				t.Fatalf("ToASCII(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestToASCII_RoundTripStable(t *testing.T) {
+	ascii, err := ToASCII("例え.テスト", Transitional)
+	if err != nil {
+		t.Fatalf("ToASCII: %v", err)
+	}
+	again, err := ToASCII(ascii, Transitional)
+	if err != nil {
+		t.Fatalf("ToASCII on already-ASCII result: %v", err)
+	}
+	if ascii != again {
+		t.Fatalf("ToASCII not idempotent: %q != %q", ascii, again)
+	}
+}
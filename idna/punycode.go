@@ -0,0 +1,114 @@
+package idna
+
+import "errors"
+
+// Punycode parameters from RFC 3492 section 5.
+const (
+	punyBase        = 36
+	punyTMin        = 1
+	punyTMax        = 26
+	punySkew        = 38
+	punyDamp        = 700
+	punyInitialBias = 72
+	punyInitialN    = 128
+)
+
+var errPunycodeOverflow = errors.New("idna: punycode overflow")
+
+// punyEncode implements the RFC 3492 encoding procedure: basic (ASCII) code
+// points are copied verbatim, followed by a '-' delimiter if there were any,
+// followed by the non-basic code points encoded as generalized variable
+// length integers in increasing order.
+func punyEncode(s string) (string, error) {
+	runes := []rune(s)
+
+	var out []byte
+	for _, r := range runes {
+		if r < 0x80 {
+			out = append(out, byte(r))
+		}
+	}
+	basicLen := len(out)
+	if basicLen > 0 {
+		out = append(out, '-')
+	}
+
+	n := punyInitialN
+	delta := 0
+	bias := punyInitialBias
+	h := basicLen
+
+	for h < len(runes) {
+		m := -1
+		for _, r := range runes {
+			if int(r) >= n && (m == -1 || int(r) < m) {
+				m = int(r)
+			}
+		}
+		if m-n > (1<<31-1-delta)/(h+1) {
+			return "", errPunycodeOverflow
+		}
+		delta += (m - n) * (h + 1)
+		n = m
+
+		for _, r := range runes {
+			if int(r) < n {
+				delta++
+				if delta < 0 {
+					return "", errPunycodeOverflow
+				}
+			}
+			if int(r) == n {
+				q := delta
+				for k := punyBase; ; k += punyBase {
+					t := punyThreshold(k, bias)
+					if q < t {
+						break
+					}
+					out = append(out, punyDigit(t+(q-t)%(punyBase-t)))
+					q = (q - t) / (punyBase - t)
+				}
+				out = append(out, punyDigit(q))
+				bias = punyAdapt(delta, h+1, h == basicLen)
+				delta = 0
+				h++
+			}
+		}
+		delta++
+		n++
+	}
+	return string(out), nil
+}
+
+func punyThreshold(k, bias int) int {
+	switch {
+	case k <= bias:
+		return punyTMin
+	case k >= bias+punyTMax:
+		return punyTMax
+	default:
+		return k - bias
+	}
+}
+
+func punyDigit(d int) byte {
+	if d < 26 {
+		return byte('a' + d)
+	}
+	return byte('0' + d - 26)
+}
+
+func punyAdapt(delta, numPoints int, firstTime bool) int {
+	if firstTime {
+		delta /= punyDamp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := 0
+	for delta > ((punyBase-punyTMin)*punyTMax)/2 {
+		delta /= punyBase - punyTMin
+		k += punyBase
+	}
+	return k + (punyBase-punyTMin+1)*delta/(delta+punySkew)
+}
@@ -0,0 +1,103 @@
+// Package puregocache is a pure Go mirror of gocache, avoiding the cgo
+// dependency on libhipermap. It implements the same fixed-capacity LRU
+// semantics (Add, Remove, Has, Dump) and a Serialize/FromSerialized pair
+// whose on-wire layout matches gocache byte-for-byte, so a cache persisted
+// by one build can be reloaded by the other regardless of build tag.
+package puregocache
+
+import (
+	"container/list"
+	"fmt"
+)
+
+// entry is the value stored in each list.Element, in most-recently-used to
+// least-recently-used order.
+type entry struct {
+	ip    uint32
+	value uint32
+}
+
+// Cache is a fixed-capacity LRU cache keyed by uint32 IP addresses. The
+// speed parameter is accepted and round-tripped through Serialize for
+// compatibility with gocache.New, but otherwise unused: it only affects the
+// internal associativity of the cgo hash table, not externally observable
+// behavior.
+type Cache struct {
+	capacity int
+	speed    int
+
+	ll    *list.List
+	items map[uint32]*list.Element
+}
+
+// New creates a Cache holding up to capacity entries.
+func New(capacity, speed int) (*Cache, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("capacity must be positive, got %d", capacity)
+	}
+
+	return &Cache{
+		capacity: capacity,
+		speed:    speed,
+		ll:       list.New(),
+		items:    make(map[uint32]*list.Element, capacity),
+	}, nil
+}
+
+// Add inserts or updates ip with value, returning whether the key already
+// existed and, if inserting it pushed the cache over capacity, whether an
+// older entry was evicted to make room (and which one).
+func (c *Cache) Add(ip, value uint32) (existed, evicted bool, evictedIp, evictedValue uint32) {
+	if el, ok := c.items[ip]; ok {
+		el.Value.(*entry).value = value
+		c.ll.MoveToFront(el)
+		return true, false, 0, 0
+	}
+
+	el := c.ll.PushFront(&entry{ip: ip, value: value})
+	c.items[ip] = el
+
+	if c.ll.Len() <= c.capacity {
+		return false, false, 0, 0
+	}
+
+	back := c.ll.Back()
+	c.ll.Remove(back)
+	ev := back.Value.(*entry)
+	delete(c.items, ev.ip)
+	return false, true, ev.ip, ev.value
+}
+
+// Remove deletes ip from the cache, returning whether it was present and,
+// if so, its value.
+func (c *Cache) Remove(ip uint32) (existed bool, existedValue uint32) {
+	el, ok := c.items[ip]
+	if !ok {
+		return false, 0
+	}
+	ev := el.Value.(*entry)
+	c.ll.Remove(el)
+	delete(c.items, ip)
+	return true, ev.value
+}
+
+// Has reports whether ip is present and, like a real LRU lookup, refreshes
+// its recency.
+func (c *Cache) Has(ip uint32) (exists bool, value uint32) {
+	el, ok := c.items[ip]
+	if !ok {
+		return false, 0
+	}
+	c.ll.MoveToFront(el)
+	return true, el.Value.(*entry).value
+}
+
+// Dump returns the IPs currently stored in the cache, most recently used
+// first.
+func (c *Cache) Dump() []uint32 {
+	ips := make([]uint32, 0, c.ll.Len())
+	for el := c.ll.Front(); el != nil; el = el.Next() {
+		ips = append(ips, el.Value.(*entry).ip)
+	}
+	return ips
+}
@@ -0,0 +1,86 @@
+package puregocache
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// magicCacheUint32 identifies the on-wire layout shared with gocache.Cache:
+// "HMCA" in little-endian bytes.
+const magicCacheUint32 = 0x41434D48
+
+// cacheHeaderBytes is the fixed-size prefix before the (ip, value) records:
+// magic, capacity, speed, count.
+const cacheHeaderBytes = 16
+
+// Serialize dumps the cache to a byte slice that can later be restored with
+// FromSerialized, either by puregocache or by gocache.FromSerialized in a
+// cgo-built binary. See gocache.Cache.Serialize for the exact on-wire
+// layout: entries are written most-recently-used first, so FromSerialized
+// can re-insert them in the same order and have the hottest keys come out
+// hottest again.
+func (c *Cache) Serialize() ([]byte, error) {
+	recs := make([]entry, 0, c.ll.Len())
+	for el := c.ll.Front(); el != nil; el = el.Next() {
+		recs = append(recs, *el.Value.(*entry))
+	}
+
+	buf := make([]byte, cacheHeaderBytes+8*len(recs))
+	binary.LittleEndian.PutUint32(buf[0:4], magicCacheUint32)
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(c.capacity))
+	binary.LittleEndian.PutUint32(buf[8:12], uint32(int32(c.speed)))
+	binary.LittleEndian.PutUint32(buf[12:16], uint32(len(recs)))
+
+	off := cacheHeaderBytes
+	for _, r := range recs {
+		binary.LittleEndian.PutUint32(buf[off:off+4], r.ip)
+		binary.LittleEndian.PutUint32(buf[off+4:off+8], r.value)
+		off += 8
+	}
+
+	return buf, nil
+}
+
+// FromSerialized restores a Cache from a byte slice produced by Serialize,
+// either by this package or by gocache.Cache.Serialize. Records are stored
+// most-recently-used first, so they are replayed back to front: the last
+// (originally least recently used) record is Add-ed first, and the first
+// (originally most recently used) record is Add-ed last, reproducing the
+// original recency order.
+func FromSerialized(data []byte) (*Cache, error) {
+	if len(data) < cacheHeaderBytes {
+		return nil, fmt.Errorf("serialized cache too short: %d bytes", len(data))
+	}
+
+	magic := binary.LittleEndian.Uint32(data[0:4])
+	if magic != magicCacheUint32 {
+		return nil, fmt.Errorf("bad magic in serialized cache: %#x", magic)
+	}
+	capacity := binary.LittleEndian.Uint32(data[4:8])
+	speed := int32(binary.LittleEndian.Uint32(data[8:12]))
+	count := binary.LittleEndian.Uint32(data[12:16])
+
+	want := cacheHeaderBytes + 8*int(count)
+	if len(data) < want {
+		return nil, fmt.Errorf("serialized cache truncated: need %d bytes, got %d", want, len(data))
+	}
+
+	c, err := New(int(capacity), int(speed))
+	if err != nil {
+		return nil, err
+	}
+
+	type rec struct{ ip, value uint32 }
+	recs := make([]rec, count)
+	off := cacheHeaderBytes
+	for i := uint32(0); i < count; i++ {
+		recs[i].ip = binary.LittleEndian.Uint32(data[off : off+4])
+		recs[i].value = binary.LittleEndian.Uint32(data[off+4 : off+8])
+		off += 8
+	}
+	for i := len(recs) - 1; i >= 0; i-- {
+		c.Add(recs[i].ip, recs[i].value)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,38 @@
+package puregocache
+
+import (
+	"fmt"
+	"io"
+)
+
+// Snapshot writes c's entries and LRU ordering to w; see gocache.Cache.Snapshot.
+func (c *Cache) Snapshot(w io.Writer) error {
+	data, err := c.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// LoadSnapshot reads a snapshot written by Snapshot; see gocache.LoadSnapshot
+// for why capacity and speed must match the values the snapshot was taken
+// with.
+func LoadSnapshot(r io.Reader, capacity, speed uint32) (*Cache, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := FromSerialized(data)
+	if err != nil {
+		return nil, err
+	}
+	if uint32(c.capacity) != capacity {
+		return nil, fmt.Errorf("LoadSnapshot: capacity mismatch: snapshot has %d, want %d", c.capacity, capacity)
+	}
+	if uint32(c.speed) != speed {
+		return nil, fmt.Errorf("LoadSnapshot: speed mismatch: snapshot has %d, want %d", c.speed, speed)
+	}
+	return c, nil
+}
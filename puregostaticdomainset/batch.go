@@ -0,0 +1,156 @@
+package puregostaticdomainset
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// chunkRanges splits [0, n) into up to workers contiguous, roughly
+// equal-sized ranges, never returning an empty range and never returning
+// more ranges than n has elements for.
+func chunkRanges(n, workers int) [][2]int {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+	if n == 0 {
+		return nil
+	}
+	size := (n + workers - 1) / workers
+	ranges := make([][2]int, 0, workers)
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges
+}
+
+// FindBatch is like FindMany, but splits queries across up to GOMAXPROCS
+// goroutines, each driving its own FindMany call over a contiguous slice.
+// This mirrors the cgo implementation's FindBatch for API parity under the
+// use_pure_gostaticdomainset build tag; this implementation has no cgo
+// boundary to amortize, but still benefits from spreading the per-query
+// loop across cores.
+func (s *StaticDomainSet) FindBatch(queries []string, out []bool) error {
+	if len(queries) != len(out) {
+		return fmt.Errorf("FindBatch: len(out)=%d != len(queries)=%d", len(out), len(queries))
+	}
+	if len(queries) == 0 {
+		return nil
+	}
+
+	ranges := chunkRanges(len(queries), runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	wg.Add(len(ranges))
+	for _, rg := range ranges {
+		start, end := rg[0], rg[1]
+		go func() {
+			defer wg.Done()
+			errs, err := s.FindMany(queries[start:end], out[start:end])
+			if err == nil && errs == nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if firstErr != nil {
+				return
+			}
+			if err != nil {
+				firstErr = err
+				return
+			}
+			for _, e := range errs {
+				if e != nil {
+					firstErr = e
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// Result is one query's outcome within a ResultSet.
+type Result struct {
+	Matched bool
+	Err     error
+}
+
+// ResultSet holds the in-flight and completed results of a SubmitBatch
+// call. Its methods are safe to call concurrently with the worker
+// goroutines still filling in the remaining slots, following the same
+// "reap whatever is ready, don't block on stragglers" shape as Tendermint's
+// TaskResultSet.Reap.
+type ResultSet struct {
+	slots []atomic.Pointer[Result]
+	wg    sync.WaitGroup
+}
+
+// SubmitBatch dispatches queries across up to GOMAXPROCS goroutines and
+// returns immediately with a ResultSet that fills in as workers finish.
+// Unlike FindBatch, callers don't have to wait for every query to resolve
+// before consuming the results that are already in.
+func (s *StaticDomainSet) SubmitBatch(queries []string) *ResultSet {
+	rs := &ResultSet{slots: make([]atomic.Pointer[Result], len(queries))}
+	ranges := chunkRanges(len(queries), runtime.GOMAXPROCS(0))
+	rs.wg.Add(len(ranges))
+	for _, rg := range ranges {
+		start, end := rg[0], rg[1]
+		go rs.fillRange(s, queries, start, end)
+	}
+	return rs
+}
+
+func (rs *ResultSet) fillRange(s *StaticDomainSet, queries []string, start, end int) {
+	defer rs.wg.Done()
+	local := make([]bool, end-start)
+	errs, err := s.FindMany(queries[start:end], local)
+	for i := start; i < end; i++ {
+		res := Result{Matched: local[i-start]}
+		if err != nil {
+			res.Err = err
+		} else if errs != nil {
+			res.Err = errs[i-start]
+		}
+		rs.slots[i].Store(&res)
+	}
+}
+
+// Reap returns the current state of every slot in query order: a non-nil
+// *Result for queries that have resolved, nil for ones still in flight.
+// It never blocks.
+func (rs *ResultSet) Reap() []*Result {
+	out := make([]*Result, len(rs.slots))
+	for i := range rs.slots {
+		out[i] = rs.slots[i].Load()
+	}
+	return out
+}
+
+// LatestResult returns the result of query i and whether it has resolved
+// yet, for streaming consumers that want to poll one slot at a time instead
+// of reaping the whole batch.
+func (rs *ResultSet) LatestResult(i int) (Result, bool) {
+	p := rs.slots[i].Load()
+	if p == nil {
+		return Result{}, false
+	}
+	return *p, true
+}
+
+// Wait blocks until every query in the batch has resolved, then returns the
+// same thing Reap would: every slot non-nil.
+func (rs *ResultSet) Wait() []*Result {
+	rs.wg.Wait()
+	return rs.Reap()
+}
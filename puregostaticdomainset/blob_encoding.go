@@ -0,0 +1,135 @@
+package puregostaticdomainset
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// encodeBlobDelta walks s.blob in the exact order buildFromPreview wrote it
+// (popular groups, then buckets, each in record order) and emits each
+// candidate domain as (shared_suffix_len, unique_prefix_len, unique_prefix)
+// relative to the previous one, varint-encoded. Candidates that are
+// adjacent in this order frequently share a long suffix, since Compile
+// groups popular suffixes together and lays out one bucket's candidates
+// next to each other.
+func encodeBlobDelta(s *StaticDomainSet) []byte {
+	var out []byte
+	var scratch [binary.MaxVarintLen64]byte
+	var prev []byte
+
+	emit := func(str []byte) {
+		shared := commonSuffixLen(prev, str)
+		unique := str[:len(str)-shared]
+
+		n := binary.PutUvarint(scratch[:], uint64(shared))
+		out = append(out, scratch[:n]...)
+		n = binary.PutUvarint(scratch[:], uint64(len(unique)))
+		out = append(out, scratch[:n]...)
+		out = append(out, unique...)
+
+		prev = str
+	}
+
+	for r := range s.popular {
+		rec := &s.popular[r]
+		for i := 0; i < int(rec.used); i++ {
+			emit(readBlobString(s.blob, rec.baseOff, rec.offsets[i]))
+		}
+	}
+	for b := range s.table {
+		rec := &s.table[b]
+		for i := 0; i < int(rec.used); i++ {
+			emit(readBlobString(s.blob, rec.baseOff, rec.offsets[i]))
+		}
+	}
+	return out
+}
+
+// decodeBlobDelta is the inverse of encodeBlobDelta: it replays the same
+// popular-groups-then-buckets order (driven by the already-deserialized
+// popular/table records' used counts, not by anything stored in data
+// itself) to reconstruct a blob byte-identical to the one Serialize would
+// have produced, so the offsets already stored in popular/table continue to
+// point at the right place.
+func decodeBlobDelta(data []byte, popular, table []domainsTableRecord) ([]byte, error) {
+	var blob []byte
+	var prev []byte
+	pos := 0
+
+	readOne := func() ([]byte, error) {
+		shared, n := binary.Uvarint(data[pos:])
+		if n <= 0 {
+			return nil, fmt.Errorf("corrupt encoded blob: shared_suffix_len")
+		}
+		pos += n
+		uniqueLen, n2 := binary.Uvarint(data[pos:])
+		if n2 <= 0 {
+			return nil, fmt.Errorf("corrupt encoded blob: unique_prefix_len")
+		}
+		pos += n2
+		if uniqueLen > uint64(len(data)-pos) {
+			return nil, fmt.Errorf("corrupt encoded blob: truncated unique prefix")
+		}
+		unique := data[pos : pos+int(uniqueLen)]
+		pos += int(uniqueLen)
+		if shared > uint64(len(prev)) {
+			return nil, fmt.Errorf("corrupt encoded blob: shared suffix longer than previous domain")
+		}
+
+		str := make([]byte, 0, int(uniqueLen)+int(shared))
+		str = append(str, unique...)
+		str = append(str, prev[len(prev)-int(shared):]...)
+		prev = str
+		return str, nil
+	}
+
+	appendString := func(str []byte) {
+		blob = append(blob, str...)
+		blob = append(blob, 0)
+		for len(blob)%16 != 0 {
+			blob = append(blob, 0)
+		}
+	}
+
+	for r := range popular {
+		for i := 0; i < int(popular[r].used); i++ {
+			str, err := readOne()
+			if err != nil {
+				return nil, err
+			}
+			appendString(str)
+		}
+	}
+	for b := range table {
+		for i := 0; i < int(table[b].used); i++ {
+			str, err := readOne()
+			if err != nil {
+				return nil, err
+			}
+			appendString(str)
+		}
+	}
+	blob = append(blob, make([]byte, blobTailPad)...)
+	return blob, nil
+}
+
+func readBlobString(blob []byte, baseOff uint32, offsetUnit uint8) []byte {
+	pos := int(baseOff) + int(offsetUnit)*dSlots
+	end := pos
+	for end < len(blob) && blob[end] != 0 {
+		end++
+	}
+	return blob[pos:end]
+}
+
+func commonSuffixLen(a, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	i := 0
+	for i < n && a[len(a)-1-i] == b[len(b)-1-i] {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,105 @@
+package puregostaticdomainset
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// FromSerializedMmap reconstructs a StaticDomainSet from buffer (typically
+// backed by an mmap'd file) without copying the domain blob: s.blob aliases
+// buffer directly instead of being duplicated onto the heap. For a 100+ MB
+// compiled blocklist, the blob is almost the entire buffer, so this turns
+// what FromSerialized does (allocate + copy the whole thing) into an
+// effectively instant load.
+//
+// Only the plain (magicUint32) format is supported: SerializeEncoded's and
+// SerializeCompressed's blobs must be decoded into a fresh buffer regardless
+// of how they were loaded, so there is nothing to alias. The table and
+// popular record slices are still copied via readRecord rather than
+// reinterpreted in place over buffer, because the on-disk record layout
+// (a packed, gap-containing mirror of the C struct, written by writeRecord)
+// does not match the field order/padding of the Go domainsTableRecord, so a
+// raw pointer cast would be both non-portable (wire format is always
+// little-endian; a big-endian host would read it wrong) and incorrect
+// (field offsets differ). Those tables are tiny next to the blob, so
+// copying them costs nothing that matters.
+//
+// The caller must call Release once done (and after every in-flight Find
+// call on this set has returned) before unmapping or otherwise invalidating
+// buffer; continuing to use the set, or unmapping buffer first, are both
+// use-after-free.
+func FromSerializedMmap(buffer []byte) (*StaticDomainSet, error) {
+	if len(buffer) < 4+headerBytes {
+		return nil, fmt.Errorf("buffer too small")
+	}
+	if binary.LittleEndian.Uint32(buffer[0:4]) != magicUint32 {
+		return nil, fmt.Errorf("FromSerializedMmap only supports the plain (uncompressed, non-delta-encoded) format")
+	}
+
+	hdr := buffer[4 : 4+headerBytes]
+	fastM := binary.LittleEndian.Uint64(hdr[0:8])
+	buckets := binary.LittleEndian.Uint32(hdr[8:12])
+	seed := binary.LittleEndian.Uint32(hdr[12:16])
+	popRecords := binary.LittleEndian.Uint32(hdr[32:36])
+	popCount := binary.LittleEndian.Uint32(hdr[36:40])
+	blobBytes := binary.LittleEndian.Uint64(hdr[48:56])
+
+	at := 4 + headerBytes
+	needRecords := int(popRecords)*recordBytes + int(buckets)*recordBytes
+	if len(buffer)-at < needRecords {
+		return nil, fmt.Errorf("buffer truncated")
+	}
+
+	s := &StaticDomainSet{
+		seed:     seed,
+		fastModM: fastM,
+		popCount: popCount,
+		popular:  make([]domainsTableRecord, popRecords),
+		table:    make([]domainsTableRecord, buckets),
+		mmapped:  true,
+	}
+
+	for i := 0; i < int(popRecords); i++ {
+		readRecord(buffer[at:at+recordBytes], &s.popular[i])
+		at += recordBytes
+	}
+	for i := 0; i < int(buckets); i++ {
+		readRecord(buffer[at:at+recordBytes], &s.table[i])
+		at += recordBytes
+	}
+
+	if blobBytes%16 != 0 || blobBytes < blobTailPad {
+		return nil, fmt.Errorf("invalid blob size")
+	}
+	if uint64(len(buffer)-at) < blobBytes {
+		return nil, fmt.Errorf("buffer truncated")
+	}
+	s.blob = buffer[at : at+int(blobBytes)]
+
+	if uint32(s.popularSuffixCount()) != popCount {
+		return nil, fmt.Errorf("popular count mismatch")
+	}
+	return s, nil
+}
+
+// Release detaches s from the buffer passed to FromSerializedMmap. It is a
+// no-op on a set built by Compile or FromSerialized, which already own
+// their data. Calling Find after Release (or after the mmap'd buffer is
+// unmapped without calling Release first) is undefined.
+func (s *StaticDomainSet) Release() {
+	if s == nil || !s.mmapped {
+		return
+	}
+	s.blob = nil
+	s.table = nil
+	s.popular = nil
+	s.mmapped = false
+}
+
+// Close is Release under the name the cgo implementation's FromSerializedMmap
+// uses. It is provided so callers that build against both implementations
+// via the use_pure_gostaticdomainset tag can call one method regardless.
+func (s *StaticDomainSet) Close() error {
+	s.Release()
+	return nil
+}
@@ -8,18 +8,21 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/starius/hipermap/idna"
 	"github.com/zeebo/xxh3"
 )
 
 // Constants mirrored from the C implementation.
 const (
-	dSlots             = 16
-	maxDomainLen       = 253
-	magicUint32        = 0x53444D48 // HMDS in little-endian bytes
-	headerBytes        = 64         // round_up64(sizeof(hm_domain_database_t)) on 64-bit
-	recordBytes        = 64         // sizeof(domains_table_record_t)
-	blobTailPad        = 256        // safety pad after blob for comparisons
-	maxPopularSuffixes = 256
+	dSlots                = 16
+	maxDomainLen          = 253
+	magicUint32           = 0x53444D48 // HMDS in little-endian bytes
+	magicEncodedUint32    = 0x53444D49 // HMDS with the low byte bumped: delta-encoded blob
+	magicCompressedUint32 = 0x53444D4A // HMDS with the low byte bumped again: dictionary-compressed blob
+	headerBytes           = 64         // round_up64(sizeof(hm_domain_database_t)) on 64-bit
+	recordBytes           = 64         // sizeof(domains_table_record_t)
+	blobTailPad           = 256        // safety pad after blob for comparisons
+	maxPopularSuffixes    = 256
 )
 
 // domainsTableRecord stores the fields needed at runtime and for serialization.
@@ -41,6 +44,134 @@ type StaticDomainSet struct {
 
 	seed     uint32
 	popCount uint32
+
+	// patterns holds the original (lowercased, dot-trimmed) domains this set
+	// was compiled from, used by FindDetails to report which one matched.
+	// Only populated via SetPatternsForSuffixMatch.
+	patterns map[string]struct{}
+
+	// idnMode is the IDN normalization Compile applied to domains, if any;
+	// Find re-applies the same mode to queries. Set via SetIDNMode. Zero
+	// value is idna.Off.
+	idnMode idna.Mode
+
+	// encodedBlobBytes is the size of the delta-encoded blob from the most
+	// recent SerializeEncoded call or, if this set was loaded via
+	// FromSerialized from an encoded buffer, the size that buffer's blob
+	// had on disk. Zero if neither has happened yet. Reported by String.
+	encodedBlobBytes int
+
+	// compressedBlobBytes/dictionaryBytes mirror encodedBlobBytes but for
+	// the dictionary-compressed format written by SerializeCompressed (or
+	// loaded via FromSerialized from one). Zero if neither has happened.
+	compressedBlobBytes int
+	dictionaryBytes     int
+
+	// mmapped is set by FromSerializedMmap: s.blob aliases the caller's
+	// buffer instead of owning a copy, so Release must be called (and Find
+	// must stop being called) before that buffer is unmapped.
+	mmapped bool
+}
+
+// SetIDNMode records the IDN normalization mode this set's Find should apply
+// to queries, mirroring the mode used on the domains it was compiled from.
+// It is called by gostaticdomainset.CompileWithOptions when
+// CompileOptions.IDN is set.
+func (s *StaticDomainSet) SetIDNMode(mode idna.Mode) {
+	s.idnMode = mode
+}
+
+// SetPatternsForSuffixMatch records the original domains this set was
+// compiled from so FindDetails can report which one matched a query. It is
+// called by gostaticdomainset.CompileWithOptions when
+// CompileOptions.SuffixMatch is set.
+func (s *StaticDomainSet) SetPatternsForSuffixMatch(domains []string) {
+	patterns := make(map[string]struct{}, len(domains))
+	for _, d := range domains {
+		for len(d) > 0 && d[len(d)-1] == '.' {
+			d = d[:len(d)-1]
+		}
+		patterns[strings.ToLower(d)] = struct{}{}
+	}
+	s.patterns = patterns
+}
+
+// FindDetails is like Find but also reports which compiled pattern matched,
+// for auditability. It is only meaningful for databases compiled with
+// CompileOptions.SuffixMatch; on other databases matchedPattern is always
+// empty.
+func (s *StaticDomainSet) FindDetails(domain string) (matched bool, matchedPattern string, err error) {
+	matched, err = s.Find(domain)
+	if err != nil || !matched || s.patterns == nil {
+		return matched, "", err
+	}
+
+	lower := strings.ToLower(strings.TrimRight(domain, "."))
+	for start := 0; ; {
+		if _, ok := s.patterns[lower[start:]]; ok {
+			return true, lower[start:], nil
+		}
+		dot := strings.IndexByte(lower[start:], '.')
+		if dot < 0 {
+			break
+		}
+		start += dot + 1
+	}
+	return true, "", nil
+}
+
+// FindMany evaluates queries against s and writes one result per query into
+// out, which must have the same length as queries. Like the cgo
+// implementation's FindMany, this is a plain per-query loop: this
+// implementation has no native batched comparison routine to amortize
+// per-call overhead against, so there is nothing to gain from grouping
+// queries by bucket here.
+//
+// A per-query failure is reported in the returned []error at the same
+// index; errs is nil if every query succeeded.
+func (s *StaticDomainSet) FindMany(queries []string, out []bool) ([]error, error) {
+	if len(queries) != len(out) {
+		return nil, fmt.Errorf("FindMany: len(out)=%d != len(queries)=%d", len(out), len(queries))
+	}
+	var errs []error
+	for i, q := range queries {
+		ok, err := s.Find(q)
+		out[i] = ok
+		if err != nil {
+			if errs == nil {
+				errs = make([]error, len(queries))
+			}
+			errs[i] = err
+		}
+	}
+	return errs, nil
+}
+
+// FindManyBytes is like FindMany but takes already-allocated byte slices.
+func (s *StaticDomainSet) FindManyBytes(queries [][]byte, out []bool) ([]error, error) {
+	strs := make([]string, len(queries))
+	for i, q := range queries {
+		strs[i] = string(q)
+	}
+	return s.FindMany(strs, out)
+}
+
+// BenchmarkMany mirrors the cgo implementation's BenchmarkMany for API
+// parity under the use_pure_gostaticdomainset build tag: it runs FindMany
+// and reports only the aggregate match count, saving a caller the trouble
+// of summing a []bool itself.
+func (s *StaticDomainSet) BenchmarkMany(queries []string) (uint64, error) {
+	out := make([]bool, len(queries))
+	if _, err := s.FindMany(queries, out); err != nil {
+		return 0, err
+	}
+	var matched uint64
+	for _, v := range out {
+		if v {
+			matched++
+		}
+	}
+	return matched, nil
 }
 
 var (
@@ -50,6 +181,7 @@ var (
 	ErrTopLevelDomain        = errors.New("top-level domains are not supported")
 	ErrTooManyPopularDomains = errors.New("too many popular domains")
 	ErrFailedToCalibrate     = errors.New("failed to calibrate")
+	ErrInvalidIDN            = errors.New("invalid IDN label")
 )
 
 // Compile builds a static domain set from a slice of domains.
@@ -94,11 +226,21 @@ func Compile(domains []string) (*StaticDomainSet, error) {
 	return s, nil
 }
 
-// Find returns whether the domain (case-insensitive) is present.
+// Find returns whether the domain (case-insensitive) is present. If s was
+// compiled with a non-Off IDN mode (see SetIDNMode), domain is first
+// normalized through the same idna.ToASCII pipeline Compile used, so a
+// Unicode query matches a punycode-stored suffix and vice versa.
 func (s *StaticDomainSet) Find(domain string) (bool, error) {
 	if s == nil || len(s.table) == 0 {
 		return false, nil
 	}
+	if s.idnMode != idna.Off {
+		ascii, err := idna.ToASCII(domain, s.idnMode)
+		if err != nil {
+			return false, ErrInvalidIDN
+		}
+		domain = ascii
+	}
 	// Trim trailing dots.
 	for len(domain) > 0 && domain[len(domain)-1] == '.' {
 		domain = domain[:len(domain)-1]
@@ -166,6 +308,22 @@ func findError(code int) error {
 	return fmt.Errorf("find failed with code %d", code)
 }
 
+// FindIDNA looks up q the same way Find does, except it always normalizes q
+// through idna.ToASCII(idna.Transitional) first regardless of the mode s
+// was compiled with, so a Unicode query matches a punycode-stored pattern
+// (and vice versa) even on a database built without SetIDNMode. ascii is
+// the normalized form that was actually looked up, mirroring the cgo
+// implementation's FindIDNA for API parity under the
+// use_pure_gostaticdomainset build tag.
+func (s *StaticDomainSet) FindIDNA(q string) (matched bool, ascii string, err error) {
+	ascii, err = idna.ToASCII(q, idna.Transitional)
+	if err != nil {
+		return false, "", ErrInvalidIDN
+	}
+	matched, err = s.Find(ascii)
+	return matched, ascii, err
+}
+
 // Seed returns the hash seed selected during calibration.
 func (s *StaticDomainSet) Seed() uint32 {
 	if s == nil {
@@ -222,12 +380,32 @@ func (s *StaticDomainSet) Serialize() ([]byte, error) {
 	return buf, nil
 }
 
-// FromSerialized reconstructs a StaticDomainSet from a compatible buffer.
+// blobFormat identifies which of the three on-disk blob encodings
+// FromSerialized is parsing.
+type blobFormat int
+
+const (
+	blobFormatPlain blobFormat = iota
+	blobFormatEncoded
+	blobFormatCompressed
+)
+
+// FromSerialized reconstructs a StaticDomainSet from a buffer produced by
+// Serialize, SerializeEncoded or SerializeCompressed; the three are told
+// apart by magic.
 func FromSerialized(buffer []byte) (*StaticDomainSet, error) {
 	if len(buffer) < 4+headerBytes {
 		return nil, fmt.Errorf("buffer too small")
 	}
-	if binary.LittleEndian.Uint32(buffer[0:4]) != magicUint32 {
+	format := blobFormatPlain
+	switch binary.LittleEndian.Uint32(buffer[0:4]) {
+	case magicUint32:
+		format = blobFormatPlain
+	case magicEncodedUint32:
+		format = blobFormatEncoded
+	case magicCompressedUint32:
+		format = blobFormatCompressed
+	default:
 		return nil, fmt.Errorf("bad magic")
 	}
 	hdr := buffer[4 : 4+headerBytes]
@@ -237,14 +415,10 @@ func FromSerialized(buffer []byte) (*StaticDomainSet, error) {
 	popRecords := binary.LittleEndian.Uint32(hdr[32:36])
 	popCount := binary.LittleEndian.Uint32(hdr[36:40])
 	blobBytes := binary.LittleEndian.Uint64(hdr[48:56])
-	if blobBytes%16 != 0 || blobBytes < blobTailPad {
-		return nil, fmt.Errorf("invalid blob size")
-	}
 
-	// Ranges
 	at := 4 + headerBytes
-	needAfterHdr := int(popRecords)*recordBytes + int(buckets)*recordBytes + int(blobBytes)
-	if len(buffer)-at < needAfterHdr {
+	needRecords := int(popRecords)*recordBytes + int(buckets)*recordBytes
+	if len(buffer)-at < needRecords {
 		return nil, fmt.Errorf("buffer truncated")
 	}
 
@@ -264,14 +438,164 @@ func FromSerialized(buffer []byte) (*StaticDomainSet, error) {
 		readRecord(buffer[at:at+recordBytes], &s.table[i])
 		at += recordBytes
 	}
-	s.blob = make([]byte, blobBytes)
-	copy(s.blob, buffer[at:at+int(blobBytes)])
+
+	switch format {
+	case blobFormatEncoded:
+		encodedBlob := buffer[at:]
+		blob, err := decodeBlobDelta(encodedBlob, s.popular, s.table)
+		if err != nil {
+			return nil, err
+		}
+		s.blob = blob
+		s.encodedBlobBytes = len(encodedBlob)
+
+	case blobFormatCompressed:
+		if len(buffer)-at < 8 {
+			return nil, fmt.Errorf("buffer truncated")
+		}
+		dictLen := binary.LittleEndian.Uint32(buffer[at : at+4])
+		compressedLen := binary.LittleEndian.Uint32(buffer[at+4 : at+8])
+		at += 8
+		if len(buffer)-at < int(dictLen)+int(compressedLen) {
+			return nil, fmt.Errorf("buffer truncated")
+		}
+		dict := buffer[at : at+int(dictLen)]
+		at += int(dictLen)
+		compressed := buffer[at : at+int(compressedLen)]
+
+		if len(dict) < dictFramingBytes || binary.LittleEndian.Uint32(dict[0:4]) != dictMagicUint32 {
+			return nil, fmt.Errorf("bad dictionary magic")
+		}
+		blob, err := decompressWithDict(compressed, dict[dictFramingBytes:], int(blobBytes))
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(blob)) != blobBytes {
+			return nil, fmt.Errorf("decompressed blob size mismatch: got %d, want %d", len(blob), blobBytes)
+		}
+		s.blob = blob
+		s.compressedBlobBytes = int(compressedLen)
+		s.dictionaryBytes = int(dictLen)
+
+	default:
+		if blobBytes%16 != 0 || blobBytes < blobTailPad {
+			return nil, fmt.Errorf("invalid blob size")
+		}
+		if len(buffer)-at < int(blobBytes) {
+			return nil, fmt.Errorf("buffer truncated")
+		}
+		s.blob = make([]byte, blobBytes)
+		copy(s.blob, buffer[at:at+int(blobBytes)])
+	}
+
 	if uint32(s.popularSuffixCount()) != popCount {
 		return nil, fmt.Errorf("popular count mismatch")
 	}
 	return s, nil
 }
 
+// SerializeEncoded is like Serialize but stores the domain blob in the
+// delta-compressed format decodeBlobDelta/encodeBlobDelta implement: each
+// domain is stored relative to the previous one in blob order as
+// (shared_suffix_len, unique_prefix_bytes) instead of verbatim, which is
+// cheap because Compile already groups popular suffixes together and lays
+// out each bucket's candidates next to each other. The magic is bumped so a
+// reader that only understands the plain format (an older build of this
+// package, or libhipermap's own hm_domain_deserialize) rejects the buffer
+// instead of misparsing it.
+//
+// FromSerialized fully decodes the blob back to the plain in-memory layout
+// at load time, so Find pays no per-lookup decoding cost either way; the
+// saving is purely in the serialized/on-disk size.
+func (s *StaticDomainSet) SerializeEncoded() ([]byte, error) {
+	if s == nil || len(s.table) == 0 {
+		return nil, fmt.Errorf("empty set")
+	}
+	encodedBlob := encodeBlobDelta(s)
+
+	popBytes := len(s.popular) * recordBytes
+	tblBytes := len(s.table) * recordBytes
+	buf := make([]byte, 4+headerBytes+popBytes+tblBytes+len(encodedBlob))
+	binary.LittleEndian.PutUint32(buf[0:4], magicEncodedUint32)
+
+	off := 4
+	binary.LittleEndian.PutUint64(buf[off+0:], s.fastModM)
+	binary.LittleEndian.PutUint32(buf[off+8:], uint32(len(s.table)))
+	binary.LittleEndian.PutUint32(buf[off+12:], s.seed)
+	binary.LittleEndian.PutUint32(buf[off+32:], uint32(len(s.popular)))
+	binary.LittleEndian.PutUint32(buf[off+36:], s.popCount)
+	binary.LittleEndian.PutUint64(buf[off+48:], uint64(len(s.blob)))
+
+	at := 4 + headerBytes
+	for i := range s.popular {
+		writeRecord(buf[at:at+recordBytes], &s.popular[i])
+		at += recordBytes
+	}
+	for i := range s.table {
+		writeRecord(buf[at:at+recordBytes], &s.table[i])
+		at += recordBytes
+	}
+	copy(buf[at:], encodedBlob)
+
+	s.encodedBlobBytes = len(encodedBlob)
+	return buf, nil
+}
+
+// SerializeCompressed is like Serialize, but the domain blob is compressed
+// against a dictionary trained from the blob's own content (see
+// trainDictionary) instead of being stored verbatim or delta-encoded. The
+// header and record tables are written exactly as Serialize writes them;
+// only the blob section differs, which is why the magic is bumped again so
+// a reader that only understands one of the other two formats rejects the
+// buffer with "bad magic" instead of misparsing it.
+//
+// This is not a spec-compliant zstd frame: it borrows zstd's idea of an
+// external dictionary trained on the corpus (see trainDictionary's doc
+// comment for the framing it emits) but compresses against that dictionary
+// with a small from-scratch LZ matcher rather than zstd's entropy stage, to
+// avoid pulling in a zstd dependency this module doesn't otherwise need.
+func (s *StaticDomainSet) SerializeCompressed() ([]byte, error) {
+	if s == nil || len(s.table) == 0 {
+		return nil, fmt.Errorf("empty set")
+	}
+	dict := trainDictionary(s, defaultMaxDictSize)
+	compressed := compressWithDict(s.blob, dict[dictFramingBytes:])
+
+	popBytes := len(s.popular) * recordBytes
+	tblBytes := len(s.table) * recordBytes
+	buf := make([]byte, 4+headerBytes+popBytes+tblBytes+8+len(dict)+len(compressed))
+	binary.LittleEndian.PutUint32(buf[0:4], magicCompressedUint32)
+
+	off := 4
+	binary.LittleEndian.PutUint64(buf[off+0:], s.fastModM)
+	binary.LittleEndian.PutUint32(buf[off+8:], uint32(len(s.table)))
+	binary.LittleEndian.PutUint32(buf[off+12:], s.seed)
+	binary.LittleEndian.PutUint32(buf[off+32:], uint32(len(s.popular)))
+	binary.LittleEndian.PutUint32(buf[off+36:], s.popCount)
+	binary.LittleEndian.PutUint64(buf[off+48:], uint64(len(s.blob)))
+
+	at := 4 + headerBytes
+	for i := range s.popular {
+		writeRecord(buf[at:at+recordBytes], &s.popular[i])
+		at += recordBytes
+	}
+	for i := range s.table {
+		writeRecord(buf[at:at+recordBytes], &s.table[i])
+		at += recordBytes
+	}
+
+	binary.LittleEndian.PutUint32(buf[at:at+4], uint32(len(dict)))
+	binary.LittleEndian.PutUint32(buf[at+4:at+8], uint32(len(compressed)))
+	at += 8
+	copy(buf[at:], dict)
+	at += len(dict)
+	copy(buf[at:], compressed)
+
+	s.compressedBlobBytes = len(compressed)
+	s.dictionaryBytes = len(dict)
+	return buf, nil
+}
+
 // String returns a summary similar to the cgo version.
 func (s *StaticDomainSet) String() string {
 	if s == nil || len(s.table) == 0 {
@@ -292,8 +616,17 @@ func (s *StaticDomainSet) String() string {
 	table := len(s.table) * recordBytes
 	blob := len(s.blob)
 	used := 4 + header + popular + table + blob
-	return fmt.Sprintf("StaticDomainSet{domains=%d, popular_hashes=%d, fill=%.1f%%, used=%d (header=%d, popular=%d, table=%d, domains=%d)}",
-		usedTotal, s.popCount, fillPct, used, header, popular, table, blob)
+	switch {
+	case s.compressedBlobBytes != 0:
+		return fmt.Sprintf("StaticDomainSet{domains=%d, popular_hashes=%d, fill=%.1f%%, used=%d (header=%d, popular=%d, table=%d, domains=%d, domains_compressed=%d, dictionary=%d)}",
+			usedTotal, s.popCount, fillPct, used, header, popular, table, blob, s.compressedBlobBytes, s.dictionaryBytes)
+	case s.encodedBlobBytes != 0:
+		return fmt.Sprintf("StaticDomainSet{domains=%d, popular_hashes=%d, fill=%.1f%%, used=%d (header=%d, popular=%d, table=%d, domains=%d, domains_encoded=%d)}",
+			usedTotal, s.popCount, fillPct, used, header, popular, table, blob, s.encodedBlobBytes)
+	default:
+		return fmt.Sprintf("StaticDomainSet{domains=%d, popular_hashes=%d, fill=%.1f%%, used=%d (header=%d, popular=%d, table=%d, domains=%d)}",
+			usedTotal, s.popCount, fillPct, used, header, popular, table, blob)
+	}
 }
 
 // Allocated returns the total size of the materialized database in bytes.
@@ -308,6 +641,22 @@ func (s *StaticDomainSet) Allocated() int {
 	return 4 + header + popular + table + blob
 }
 
+// PopularContainerStats mirrors the cgo implementation's per-container-type
+// breakdown of the popular-hash presence bitmap. This implementation keeps
+// popular suffixes as a plain sorted record slice instead of a Roaring-style
+// bitmap, so it is always the zero value.
+type PopularContainerStats struct {
+	ArrayContainers  int
+	BitmapContainers int
+	RunContainers    int
+}
+
+// PopularContainerStats always returns the zero value; see
+// PopularContainerStats.
+func (s *StaticDomainSet) PopularContainerStats() PopularContainerStats {
+	return PopularContainerStats{}
+}
+
 func (s *StaticDomainSet) popularSuffixCount() int {
 	if s == nil {
 		return 0
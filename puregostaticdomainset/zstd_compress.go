@@ -0,0 +1,149 @@
+package puregostaticdomainset
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// compressWithDict encodes data as a sequence of literal runs and matches
+// against dictWindow, using a simple LZ matcher rather than zstd's entropy
+// stage (see trainDictionary's doc comment). Matches are only ever found
+// against dictWindow, not against data's own earlier output, which keeps
+// the matcher a single static-window lookup at the cost of some ratio a
+// full LZ77 self-reference would recover.
+//
+// Token stream format: a literal run is tag byte 0, a uvarint length, then
+// that many raw bytes. A match is tag byte 1, a uvarint offset into
+// dictWindow, then a uvarint length.
+func compressWithDict(data, dictWindow []byte) []byte {
+	const minMatch = 4
+	const maxCandidates = 8
+
+	index := make(map[uint32][]int32)
+	if len(dictWindow) >= minMatch {
+		for i := 0; i+minMatch <= len(dictWindow); i++ {
+			key := binary.LittleEndian.Uint32(dictWindow[i : i+minMatch])
+			index[key] = append(index[key], int32(i))
+		}
+	}
+
+	var out []byte
+	var scratch [binary.MaxVarintLen64]byte
+	litStart := 0
+	flushLiteral := func(end int) {
+		if end <= litStart {
+			return
+		}
+		out = append(out, 0)
+		n := binary.PutUvarint(scratch[:], uint64(end-litStart))
+		out = append(out, scratch[:n]...)
+		out = append(out, data[litStart:end]...)
+	}
+
+	pos := 0
+	for pos+minMatch <= len(data) {
+		key := binary.LittleEndian.Uint32(data[pos : pos+minMatch])
+		cands := index[key]
+
+		bestLen, bestOff, tries := 0, 0, 0
+		for i := len(cands) - 1; i >= 0 && tries < maxCandidates; i-- {
+			tries++
+			dpos := int(cands[i])
+			l := matchLen(dictWindow[dpos:], data[pos:])
+			if l > bestLen {
+				bestLen, bestOff = l, dpos
+			}
+		}
+
+		if bestLen >= minMatch {
+			flushLiteral(pos)
+			out = append(out, 1)
+			n := binary.PutUvarint(scratch[:], uint64(bestOff))
+			out = append(out, scratch[:n]...)
+			n = binary.PutUvarint(scratch[:], uint64(bestLen))
+			out = append(out, scratch[:n]...)
+			pos += bestLen
+			litStart = pos
+			continue
+		}
+		pos++
+	}
+	flushLiteral(len(data))
+	return out
+}
+
+// decompressWithDictMaxInitialCap bounds how much decompressWithDict will
+// allocate up front on the strength of the caller-supplied originalLen
+// alone; originalLen comes from a serialized header in FromSerialized's
+// compressed branch and is unverified until decoding finishes, so a
+// corrupt header claiming an enormous size must not force an enormous
+// allocation before the (possibly tiny) token stream is even parsed.
+// Legitimate blobs larger than this just grow past it via append, same
+// as if originalLen had been omitted entirely.
+const decompressWithDictMaxInitialCap = 1 << 24
+
+// decompressWithDict is the inverse of compressWithDict. originalLen only
+// sizes the output buffer up front; the actual length comes from replaying
+// the token stream, and decompressWithDictMaxInitialCap caps how much of
+// that hint is trusted before the stream itself is decoded.
+func decompressWithDict(tokens, dictWindow []byte, originalLen int) ([]byte, error) {
+	initialCap := originalLen
+	if initialCap < 0 || initialCap > decompressWithDictMaxInitialCap {
+		initialCap = decompressWithDictMaxInitialCap
+	}
+	out := make([]byte, 0, initialCap)
+	pos := 0
+	for pos < len(tokens) {
+		tag := tokens[pos]
+		pos++
+		switch tag {
+		case 0:
+			l, n := binary.Uvarint(tokens[pos:])
+			if n <= 0 {
+				return nil, fmt.Errorf("corrupt compressed blob: literal length")
+			}
+			pos += n
+			if l > uint64(len(tokens)-pos) {
+				return nil, fmt.Errorf("corrupt compressed blob: truncated literal")
+			}
+			out = append(out, tokens[pos:pos+int(l)]...)
+			pos += int(l)
+
+		case 1:
+			off, n := binary.Uvarint(tokens[pos:])
+			if n <= 0 {
+				return nil, fmt.Errorf("corrupt compressed blob: match offset")
+			}
+			pos += n
+			l, n2 := binary.Uvarint(tokens[pos:])
+			if n2 <= 0 {
+				return nil, fmt.Errorf("corrupt compressed blob: match length")
+			}
+			pos += n2
+			// Checked independently, not as off+l > len(dictWindow): with
+			// off and l both attacker-controlled uvarints, their sum can
+			// wrap past MaxUint64 and pass a combined check even though
+			// dictWindow[off:off+l] is out of range.
+			if off > uint64(len(dictWindow)) || l > uint64(len(dictWindow))-off {
+				return nil, fmt.Errorf("corrupt compressed blob: match out of dictionary bounds")
+			}
+			out = append(out, dictWindow[off:off+l]...)
+
+		default:
+			return nil, fmt.Errorf("corrupt compressed blob: bad tag %d", tag)
+		}
+	}
+	return out, nil
+}
+
+func matchLen(a, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	i := 0
+	for i < n && a[i] == b[i] {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,149 @@
+package puregostaticdomainset
+
+import (
+	"encoding/binary"
+	"sort"
+	"strings"
+
+	"github.com/zeebo/xxh3"
+)
+
+// dictMagicUint32 identifies the dictionary format trainDictionary writes:
+// "DDIC" in little-endian bytes. It is deliberately not the standard zstd
+// dictionary magic (RFC 8878 section 5): this format only borrows zstd's
+// idea of a raw-content dictionary, not its framing, and compressWithDict
+// does not implement zstd's entropy stage (see the doc comment below), so
+// stamping the real zstd magic on it would mislead anyone who extracted the
+// blob and fed it to an actual zstd tool.
+const dictMagicUint32 = 0x43494444
+
+// dictFramingBytes is the size of the framing header trainDictionary
+// writes ahead of the dictionary content: magic, dict ID, entropy table
+// length.
+const dictFramingBytes = 12
+
+// defaultMaxDictSize bounds how large a trained dictionary SerializeCompressed
+// builds, including its framing header.
+const defaultMaxDictSize = 8 * 1024
+
+// minSubstrLen/maxSubstrLen bound the substring lengths trainDictionary
+// scores when picking dictionary entries.
+const (
+	minSubstrLen = 4
+	maxSubstrLen = 16
+)
+
+// dictCandidate is a scored substring considered for inclusion in the
+// trained dictionary.
+type dictCandidate struct {
+	data  string
+	count int
+}
+
+// trainDictionary builds a small zstd-style dictionary from the domain
+// blob's own content: every substring of length minSubstrLen..maxSubstrLen
+// across all popular and bucket candidates is hashed with xxh3 into a
+// frequency table, scored by (occurrences-1)*length, and the top entries
+// (by budget, not count) are kept, skipping any entry that is a substring
+// of one already kept.
+//
+// The returned buffer starts with dictFramingBytes of framing: dictMagicUint32,
+// a 4-byte dictionary ID derived from hashing the blob, and a 4-byte
+// entropy-table length left at zero. A zero
+// entropy-table length signals that this dictionary carries no trained
+// FSE/Huffman tables of its own, unlike a real zstd --train dictionary;
+// compressWithDict only uses the raw dictionary content as a match window,
+// it does not implement zstd's entropy stage. Entries are laid out in
+// ascending score order, so the strongest entries land closest to the end
+// of the window, mirroring zstd's preference for recent back-references.
+func trainDictionary(s *StaticDomainSet, maxDictSize int) []byte {
+	freq := make(map[uint64]*dictCandidate)
+
+	add := func(str []byte) {
+		n := len(str)
+		for l := minSubstrLen; l <= maxSubstrLen && l <= n; l++ {
+			for i := 0; i+l <= n; i++ {
+				sub := str[i : i+l]
+				h := xxh3.Hash(sub)
+				if c, ok := freq[h]; ok {
+					c.count++
+				} else {
+					freq[h] = &dictCandidate{data: string(sub), count: 1}
+				}
+			}
+		}
+	}
+
+	for r := range s.popular {
+		rec := &s.popular[r]
+		for i := 0; i < int(rec.used); i++ {
+			add(readBlobString(s.blob, rec.baseOff, rec.offsets[i]))
+		}
+	}
+	for b := range s.table {
+		rec := &s.table[b]
+		for i := 0; i < int(rec.used); i++ {
+			add(readBlobString(s.blob, rec.baseOff, rec.offsets[i]))
+		}
+	}
+
+	candidates := make([]*dictCandidate, 0, len(freq))
+	for _, c := range freq {
+		if c.count > 1 {
+			candidates = append(candidates, c)
+		}
+	}
+	score := func(c *dictCandidate) int { return (c.count - 1) * len(c.data) }
+	sort.Slice(candidates, func(i, j int) bool {
+		si, sj := score(candidates[i]), score(candidates[j])
+		if si != sj {
+			return si > sj
+		}
+		return candidates[i].data < candidates[j].data
+	})
+
+	budget := maxDictSize - dictFramingBytes
+	var selected []*dictCandidate
+	for _, c := range candidates {
+		if budget <= 0 {
+			break
+		}
+		if len(c.data) > budget {
+			continue
+		}
+		redundant := false
+		for _, sel := range selected {
+			if strings.Contains(sel.data, c.data) {
+				redundant = true
+				break
+			}
+		}
+		if redundant {
+			continue
+		}
+		selected = append(selected, c)
+		budget -= len(c.data)
+	}
+
+	// Lay out ascending by score: the highest-scoring entries end up
+	// nearest the end of the window.
+	sort.Slice(selected, func(i, j int) bool { return score(selected[i]) < score(selected[j]) })
+
+	var body strings.Builder
+	for _, c := range selected {
+		body.WriteString(c.data)
+	}
+
+	out := make([]byte, dictFramingBytes+body.Len())
+	binary.LittleEndian.PutUint32(out[0:4], dictMagicUint32)
+	binary.LittleEndian.PutUint32(out[4:8], uint32(xxh3.HashString(s.blobAsCorpus())))
+	binary.LittleEndian.PutUint32(out[8:12], 0) // entropy table length: none, see doc comment
+	copy(out[dictFramingBytes:], body.String())
+	return out
+}
+
+// blobAsCorpus exposes s.blob as a string, used only to derive a stable
+// dictionary ID via hashing.
+func (s *StaticDomainSet) blobAsCorpus() string {
+	return string(s.blob)
+}